@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"loan-management/internal/domain"
+	"loan-management/internal/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+type ActionRequestController struct {
+	actionRequestUsecase domain.ActionRequestUsecase
+}
+
+func NewActionRequestController(db mongoifc.Database) ActionRequestController {
+	usecase := usecases.NewActionRequestUsecase(db)
+	return ActionRequestController{actionRequestUsecase: usecase}
+}
+
+func (c *ActionRequestController) GetActionRequests(ctx *gin.Context) {
+	requests, err := c.actionRequestUsecase.GetActionRequests(ctx.Query("status"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, requests)
+}
+
+func (c *ActionRequestController) GetActionRequest(ctx *gin.Context) {
+	request, err := c.actionRequestUsecase.GetActionRequest(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, request)
+}
+
+func (c *ActionRequestController) ApproveActionRequest(ctx *gin.Context) {
+	checkerID, _ := ctx.Get("userID")
+	roles, _ := ctx.Get("roles")
+	checkerRoles, _ := roles.([]domain.Role)
+	request, err := c.actionRequestUsecase.Approve(ctx.Param("id"), checkerID.(string), checkerRoles)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "action_request": request})
+		return
+	}
+	ctx.JSON(http.StatusOK, request)
+}
+
+func (c *ActionRequestController) RejectActionRequest(ctx *gin.Context) {
+	checkerID, _ := ctx.Get("userID")
+	roles, _ := ctx.Get("roles")
+	checkerRoles, _ := roles.([]domain.Role)
+	input := struct {
+		Note string `json:"note"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	request, err := c.actionRequestUsecase.Reject(ctx.Param("id"), checkerID.(string), checkerRoles, input.Note)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, request)
+}
+
+// performAction files a sensitive action and writes the response when it is
+// left pending for a second admin or fails. It reports whether the action
+// was executed, in which case the caller writes the response.
+func performAction(ctx *gin.Context, usecase domain.ActionRequestUsecase, request domain.ActionRequest) bool {
+	makerID, _ := ctx.Get("userID")
+	request.RequestedBy = makerID.(string)
+	request, err := usecase.Perform(request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if request.Status == domain.ActionStatusPending {
+		ctx.JSON(http.StatusAccepted, gin.H{"message": "action requires approval by a second admin", "action_request": request})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"errors"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/internal/usecases"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+type APIKeyController struct {
+	apiKeyUsecase domain.APIKeyUsecase
+}
+
+func NewAPIKeyController(db mongoifc.Database) APIKeyController {
+	usecase := usecases.NewAPIKeyUsecase(db)
+	return APIKeyController{apiKeyUsecase: usecase}
+}
+
+func (c *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+	actorID, _ := ctx.Get("userID")
+	input := struct {
+		Name      string            `json:"name" binding:"required"`
+		UserID    string            `json:"user_id" binding:"required"`
+		Scopes    []domain.APIScope `json:"scopes" binding:"required"`
+		RateLimit int               `json:"rate_limit"`
+		ExpiresAt time.Time         `json:"expires_at"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	key, secret, err := c.apiKeyUsecase.CreateAPIKey(actorID.(string), domain.APIKey{
+		Name:      input.Name,
+		UserID:    input.UserID,
+		Scopes:    input.Scopes,
+		RateLimit: input.RateLimit,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"api_key": key, "key": secret})
+}
+
+func (c *APIKeyController) GetAPIKeys(ctx *gin.Context) {
+	keys, err := c.apiKeyUsecase.GetAPIKeys(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+	actorID, _ := ctx.Get("userID")
+	err := c.apiKeyUsecase.RevokeAPIKey(actorID.(string), ctx.Param("id"))
+	if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"loan-management/internal/domain"
+	"loan-management/internal/usecases"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+type CollateralController struct {
+	collateralUsecase domain.CollateralUsecase
+}
+
+func NewCollateralController(db mongoifc.Database) CollateralController {
+	usecase := usecases.NewCollateralUsecase(db)
+	return CollateralController{collateralUsecase: usecase}
+}
+
+func (c *CollateralController) AddCollateral(ctx *gin.Context) {
+	loanID := ctx.Param("id")
+	collateral := domain.Collateral{}
+	if err := ctx.ShouldBindJSON(&collateral); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	collateral, err := c.collateralUsecase.AddCollateral(loanID, collateral)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, collateral)
+}
+
+func (c *CollateralController) GetLoanCollaterals(ctx *gin.Context) {
+	loanID := ctx.Param("id")
+	ltv, err := c.collateralUsecase.GetLoanToValue(loanID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, ltv)
+}
+
+func (c *CollateralController) GetCollateral(ctx *gin.Context) {
+	collateral, err := c.collateralUsecase.GetCollateral(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, collateral)
+}
+
+func (c *CollateralController) UpdateCollateral(ctx *gin.Context) {
+	updateData := struct {
+		Type           string    `json:"type"`
+		Description    string    `json:"description"`
+		AppraisedValue float64   `json:"appraised_value"`
+		ValuationDate  time.Time `json:"valuation_date"`
+		LienStatus     string    `json:"lien_status"`
+	}{}
+	if err := ctx.ShouldBindJSON(&updateData); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	collateral, err := c.collateralUsecase.UpdateCollateral(ctx.Param("id"), domain.Collateral{
+		Type:           updateData.Type,
+		Description:    updateData.Description,
+		AppraisedValue: updateData.AppraisedValue,
+		ValuationDate:  updateData.ValuationDate,
+		LienStatus:     updateData.LienStatus,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, collateral)
+}
+
+func (c *CollateralController) DeleteCollateral(ctx *gin.Context) {
+	if err := c.collateralUsecase.DeleteCollateral(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "collateral deleted successfully"})
+}
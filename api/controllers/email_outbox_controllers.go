@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"errors"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/internal/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+type EmailOutboxController struct {
+	outboxUsecase domain.EmailOutboxUsecase
+}
+
+func NewEmailOutboxController(db mongoifc.Database) EmailOutboxController {
+	usecase := usecases.NewEmailOutboxUsecase(db)
+	return EmailOutboxController{outboxUsecase: usecase}
+}
+
+func (c *EmailOutboxController) GetDeadLetters(ctx *gin.Context) {
+	emails, err := c.outboxUsecase.GetDeadLetters()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, emails)
+}
+
+func (c *EmailOutboxController) RetryDeadLetter(ctx *gin.Context) {
+	actorID, _ := ctx.Get("userID")
+	email, err := c.outboxUsecase.RetryDeadLetter(actorID.(string), ctx.Param("id"))
+	if errors.Is(err, repositories.ErrOutboxEmailNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, email)
+}
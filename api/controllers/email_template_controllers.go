@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"errors"
+	"loan-management/internal/domain"
+	"loan-management/internal/usecases"
+	"loan-management/pkg/infrastructures"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailTemplateController struct {
+	emailTemplateUsecase domain.EmailTemplateUsecase
+}
+
+func NewEmailTemplateController() EmailTemplateController {
+	usecase := usecases.NewEmailTemplateUsecase()
+	return EmailTemplateController{emailTemplateUsecase: usecase}
+}
+
+func (c *EmailTemplateController) GetEmailTemplates(ctx *gin.Context) {
+	templates, err := c.emailTemplateUsecase.GetEmailTemplates()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, templates)
+}
+
+// PreviewEmailTemplate returns the rendered email as JSON, or only its HTML
+// or text body with ?format=html or ?format=text for viewing in a browser.
+func (c *EmailTemplateController) PreviewEmailTemplate(ctx *gin.Context) {
+	preview, err := c.emailTemplateUsecase.PreviewEmailTemplate(ctx.Param("name"), ctx.Query("locale"))
+	if errors.Is(err, infrastructures.ErrEmailTemplateNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	switch ctx.Query("format") {
+	case "html":
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(preview.HTML))
+	case "text":
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(preview.Text))
+	default:
+		ctx.JSON(http.StatusOK, preview)
+	}
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"loan-management/internal/domain"
+	"loan-management/internal/usecases"
+	"loan-management/pkg/infrastructures"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+type JWKSController struct {
+	signingKeyUsecase domain.SigningKeyUsecase
+}
+
+func NewJWKSController(db mongoifc.Database) JWKSController {
+	usecase := usecases.NewSigningKeyUsecase(db)
+	return JWKSController{signingKeyUsecase: usecase}
+}
+
+// GetJWKS publishes the public keys that currently verify access tokens.
+func (c *JWKSController) GetJWKS(ctx *gin.Context) {
+	keys, err := c.signingKeyUsecase.GetValidKeys()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	jwks := []infrastructures.JSONWebKey{}
+	for _, key := range keys {
+		publicKey, err := infrastructures.ParsePublicKey(key.PublicKey)
+		if err != nil {
+			continue
+		}
+		jwk, err := infrastructures.PublicJWK(key.ID, key.Algorithm, publicKey)
+		if err != nil {
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, gin.H{"keys": jwks})
+}
@@ -1,21 +1,27 @@
 package controllers
 
 import (
+	"errors"
 	"loan-management/internal/domain"
 	"loan-management/internal/usecases"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sv-tools/mongoifc"
 )
 
 type LoanController struct {
-	loanUsecase domain.LoanUsecase
+	loanUsecase          domain.LoanUsecase
+	accrualUsecase       domain.InterestAccrualUsecase
+	actionRequestUsecase domain.ActionRequestUsecase
 }
 
 func NewLoanController(db mongoifc.Database) LoanController {
 	usecase := usecases.NewLoanUsecase(db)
-	return LoanController{loanUsecase: usecase}
+	accrualUsecase := usecases.NewInterestAccrualUsecase(db)
+	actionRequestUsecase := usecases.NewActionRequestUsecase(db)
+	return LoanController{loanUsecase: usecase, accrualUsecase: accrualUsecase, actionRequestUsecase: actionRequestUsecase}
 }
 
 func (c *LoanController) CreateLoan(ctx *gin.Context) {
@@ -25,7 +31,11 @@ func (c *LoanController) CreateLoan(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotAcceptable, gin.H{"message": "invalid data format"})
 		return
 	}
-	loan, err := c.loanUsecase.CreateLoan(userID.(string), loan.Ammount)
+	loan, err := c.loanUsecase.CreateLoan(userID.(string), loan)
+	if errors.Is(err, usecases.ErrTermTooLong) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -75,11 +85,20 @@ func (c *LoanController) ApproveRejectLoan(ctx *gin.Context) {
 		return
 	}
 	if st == "approve" {
-		st = "approved"
-	} else {
-		st = st + "d"
+		// Large approvals may need a second admin.
+		request := domain.ActionRequest{Type: domain.ActionLoanApproval, TargetID: loanID}
+		if !performAction(ctx, c.actionRequestUsecase, request) {
+			return
+		}
+		loan, err := c.loanUsecase.ViewLoanStatus(loanID)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, loan)
+		return
 	}
-	updatedLoan, err := c.loanUsecase.ApproveRejectLoan(loanID, st)
+	updatedLoan, err := c.loanUsecase.ApproveRejectLoan(loanID, st+"d")
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -88,11 +107,73 @@ func (c *LoanController) ApproveRejectLoan(ctx *gin.Context) {
 }
 
 func (c *LoanController) DeleteLoan(ctx *gin.Context) {
+	request := domain.ActionRequest{Type: domain.ActionLoanDeletion, TargetID: ctx.Param("id")}
+	if !performAction(ctx, c.actionRequestUsecase, request) {
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "loan deleted successfully"})
+}
+
+func (c *LoanController) WriteOffLoan(ctx *gin.Context) {
+	input := struct {
+		Reason string `json:"reason" binding:"required"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "reason field is required"})
+		return
+	}
 	loanID := ctx.Param("id")
-	err := c.loanUsecase.DeleteLoan(loanID)
+	request := domain.ActionRequest{Type: domain.ActionLoanWriteOff, TargetID: loanID, Reason: input.Reason}
+	if !performAction(ctx, c.actionRequestUsecase, request) {
+		return
+	}
+	loan, err := c.loanUsecase.ViewLoanStatus(loanID)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "loan deleted successfully"})
+	ctx.JSON(http.StatusOK, loan)
+}
+
+func (c *LoanController) ViewLoanAccruals(ctx *gin.Context) {
+	accruals, err := c.accrualUsecase.GetLoanAccruals(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, accruals)
+}
+
+func (c *LoanController) RecordPayment(ctx *gin.Context) {
+	number, err := strconv.Atoi(ctx.Param("number"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid installment number"})
+		return
+	}
+	loan, err := c.loanUsecase.RecordPayment(ctx.Param("id"), number)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, loan)
+}
+
+func (c *LoanController) AcceptOffer(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	loan, err := c.loanUsecase.AcceptOffer(ctx.Param("id"), userID.(string))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, loan)
+}
+
+func (c *LoanController) DeclineOffer(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	loan, err := c.loanUsecase.DeclineOffer(ctx.Param("id"), userID.(string))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, loan)
 }
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"errors"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/internal/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+type NotificationController struct {
+	notificationUsecase domain.NotificationUsecase
+}
+
+func NewNotificationController(db mongoifc.Database) NotificationController {
+	usecase := usecases.NewNotificationUsecase(db)
+	return NotificationController{notificationUsecase: usecase}
+}
+
+func (c *NotificationController) GetNotifications(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	notifications, err := c.notificationUsecase.GetNotifications(userID.(string))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, notifications)
+}
+
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	err := c.notificationUsecase.MarkRead(userID.(string), ctx.Param("id"))
+	if errors.Is(err, repositories.ErrNotificationNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "notification marked as read"})
+}
+
+func (c *NotificationController) GetPreferences(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	preferences, err := c.notificationUsecase.GetPreferences(userID.(string))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, preferences)
+}
+
+func (c *NotificationController) UpdatePreferences(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	enabled := map[domain.NotificationType]bool{}
+	if err := ctx.ShouldBindJSON(&enabled); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	preferences, err := c.notificationUsecase.UpdatePreferences(userID.(string), enabled)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, preferences)
+}
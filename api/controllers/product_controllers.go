@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"errors"
+	"loan-management/internal/domain"
+	"loan-management/internal/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+type ProductController struct {
+	productUsecase   domain.LoanProductUsecase
+	benchmarkUsecase domain.BenchmarkRateUsecase
+}
+
+func NewProductController(db mongoifc.Database) ProductController {
+	return ProductController{
+		productUsecase:   usecases.NewLoanProductUsecase(db),
+		benchmarkUsecase: usecases.NewBenchmarkRateUsecase(db),
+	}
+}
+
+func (c *ProductController) GetProducts(ctx *gin.Context) {
+	products, err := c.productUsecase.GetProducts()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, products)
+}
+
+func (c *ProductController) GetProduct(ctx *gin.Context) {
+	product, err := c.productUsecase.GetProduct(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, product)
+}
+
+func (c *ProductController) CreateProduct(ctx *gin.Context) {
+	product := domain.LoanProduct{}
+	if err := ctx.ShouldBindJSON(&product); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	product, err := c.productUsecase.CreateProduct(product)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, product)
+}
+
+func (c *ProductController) UpdateProduct(ctx *gin.Context) {
+	updateData := struct {
+		Name                 string  `json:"name"`
+		RateType             string  `json:"rate_type"`
+		FixedRate            float64 `json:"fixed_rate"`
+		Benchmark            string  `json:"benchmark"`
+		Margin               float64 `json:"margin"`
+		ResetFrequencyMonths int     `json:"reset_frequency_months"`
+		TermMonths           int     `json:"term_months"`
+		OriginationFee       float64 `json:"origination_fee"`
+		OriginationFeeRate   float64 `json:"origination_fee_rate"`
+		DayCountConvention   string  `json:"day_count_convention"`
+	}{}
+	if err := ctx.ShouldBindJSON(&updateData); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	product, err := c.productUsecase.UpdateProduct(ctx.Param("id"), domain.LoanProduct{
+		Name:                 updateData.Name,
+		RateType:             updateData.RateType,
+		FixedRate:            updateData.FixedRate,
+		Benchmark:            updateData.Benchmark,
+		Margin:               updateData.Margin,
+		ResetFrequencyMonths: updateData.ResetFrequencyMonths,
+		TermMonths:           updateData.TermMonths,
+		OriginationFee:       updateData.OriginationFee,
+		OriginationFeeRate:   updateData.OriginationFeeRate,
+		DayCountConvention:   updateData.DayCountConvention,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, product)
+}
+
+func (c *ProductController) DeleteProduct(ctx *gin.Context) {
+	err := c.productUsecase.DeleteProduct(ctx.Param("id"))
+	if errors.Is(err, usecases.ErrProductInUse) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "loan product deleted successfully"})
+}
+
+func (c *ProductController) GetBenchmarkRates(ctx *gin.Context) {
+	benchmark := ctx.Query("benchmark")
+	if benchmark == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "benchmark query parameter is required"})
+		return
+	}
+	rates, err := c.benchmarkUsecase.GetRates(benchmark)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, rates)
+}
+
+func (c *ProductController) AddBenchmarkRate(ctx *gin.Context) {
+	rate := domain.BenchmarkRate{}
+	if err := ctx.ShouldBindJSON(&rate); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	rate, err := c.benchmarkUsecase.AddRate(rate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, rate)
+}
+
+func (c *ProductController) DeleteBenchmarkRate(ctx *gin.Context) {
+	if err := c.benchmarkUsecase.DeleteRate(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "benchmark rate deleted successfully"})
+}
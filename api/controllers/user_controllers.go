@@ -1,23 +1,28 @@
 package controllers
 
 import (
+	"errors"
 	"loan-management/internal/domain"
 	"loan-management/internal/usecases"
-	"loan-management/pkg/infrastructures"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sv-tools/mongoifc"
 )
 
 type UserController struct {
-	userUsecase domain.UserUsecases
+	userUsecase          domain.UserUsecases
+	sessionUsecase       domain.SessionUsecase
+	actionRequestUsecase domain.ActionRequestUsecase
+	oidcUsecase          domain.OIDCUsecase
 }
 
 func NewUserController(db mongoifc.Database) UserController {
 	usecase := usecases.NewUserUsecase(db)
-	return UserController{userUsecase: usecase}
+	sessionUsecase := usecases.NewSessionUsecase(db)
+	actionRequestUsecase := usecases.NewActionRequestUsecase(db)
+	oidcUsecase := usecases.NewOIDCUsecase(db)
+	return UserController{userUsecase: usecase, sessionUsecase: sessionUsecase, actionRequestUsecase: actionRequestUsecase, oidcUsecase: oidcUsecase}
 }
 
 func (uc *UserController) SignUp(ctx *gin.Context) {
@@ -45,6 +50,36 @@ func (uc *UserController) VerifyEmail(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "account activated successfully"})
 }
 
+func (uc *UserController) ResendVerification(ctx *gin.Context) {
+	input := struct {
+		Email string `json:"email" binding:"required,email"`
+	}{}
+	if err := ctx.ShouldBind(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	err := uc.userUsecase.ResendVerification(input.Email)
+	if errors.Is(err, usecases.ErrResendThrottled) {
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "if the account is awaiting verification, a new link has been sent"})
+}
+
+func (uc *UserController) UnlockAccount(ctx *gin.Context) {
+	token := ctx.Query("token")
+	email := ctx.Query("email")
+	if err := uc.userUsecase.UnlockAccount(token, email); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "account unlocked successfully"})
+}
+
 func (uc *UserController) Login(ctx *gin.Context) {
 	credentials := struct {
 		Email    string `json:"email" binding:"required"`
@@ -54,30 +89,175 @@ func (uc *UserController) Login(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid credentials"})
 		return
 	}
-	user, err := uc.userUsecase.Login(credentials.Email, credentials.Password)
+	user, challenge, err := uc.userUsecase.Login(credentials.Email, credentials.Password, ctx.ClientIP())
+	if errors.Is(err, usecases.ErrLoginThrottled) {
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if challenge != "" {
+		ctx.JSON(http.StatusOK, gin.H{"mfa_required": true, "mfa_token": challenge})
+		return
+	}
+	tokens, err := uc.sessionUsecase.CreateSession(user, ctx.Request.UserAgent(), ctx.ClientIP(), false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func (uc *UserController) VerifyMFA(ctx *gin.Context) {
+	input := struct {
+		MFAToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "mfa_token and code fields are required"})
+		return
+	}
+	user, err := uc.userUsecase.VerifyMFA(input.MFAToken, input.Code, ctx.ClientIP())
+	if errors.Is(err, usecases.ErrLoginThrottled) {
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := uc.sessionUsecase.CreateSession(user, ctx.Request.UserAgent(), ctx.ClientIP(), true)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func (uc *UserController) StartOIDCLogin(ctx *gin.Context) {
+	authURL, err := uc.oidcUsecase.StartLogin()
+	if errors.Is(err, usecases.ErrOIDCDisabled) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+func (uc *UserController) OIDCCallback(ctx *gin.Context) {
+	if providerError := ctx.Query("error"); providerError != "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": providerError, "error_description": ctx.Query("error_description")})
+		return
+	}
+	user, mfa, err := uc.oidcUsecase.CompleteLogin(ctx.Query("state"), ctx.Query("code"))
+	if errors.Is(err, usecases.ErrOIDCDisabled) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
-	refreshToken, err := infrastructures.GenerateJWTToken(user, (7 * time.Hour))
-	accessToken, err := infrastructures.GenerateJWTToken(user, (1 * time.Hour))
-	ctx.JSON(http.StatusOK, gin.H{"refresh_token": refreshToken, "access_token": accessToken})
+	tokens, err := uc.sessionUsecase.CreateSession(user, ctx.Request.UserAgent(), ctx.ClientIP(), mfa)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func (uc *UserController) EnrollMFA(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	enrollment, err := uc.userUsecase.EnrollMFA(userID.(string))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, enrollment)
+}
+
+func (uc *UserController) ConfirmMFA(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	input := struct {
+		Code string `json:"code" binding:"required"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "code field is required"})
+		return
+	}
+	codes, err := uc.userUsecase.ConfirmMFA(userID.(string), input.Code)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "multi-factor authentication enabled", "recovery_codes": codes})
+}
+
+func (uc *UserController) DisableMFA(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	input := struct {
+		Code string `json:"code" binding:"required"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "code field is required"})
+		return
+	}
+	if err := uc.userUsecase.DisableMFA(userID.(string), input.Code); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "multi-factor authentication disabled"})
 }
 
 func (uc *UserController) RefreshAccessToken(ctx *gin.Context) {
 	token := struct {
-		RefreshToken string `json:"refresh_token"`
+		RefreshToken string `json:"refresh_token" binding:"required"`
 	}{}
 	if err := ctx.ShouldBind(&token); err != nil {
 		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "refresh_token field is required"})
 		return
 	}
-	accessToken, err := uc.userUsecase.RefreshAccessToken(token.RefreshToken)
+	tokens, err := uc.sessionUsecase.Refresh(token.RefreshToken, ctx.ClientIP())
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func (uc *UserController) Logout(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	sessionID, _ := ctx.Get("sessionID")
+	if err := uc.sessionUsecase.RevokeSession(userID.(string), sessionID.(string)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"access_token": accessToken})
+	ctx.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
+}
+
+func (uc *UserController) GetSessions(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	sessionID, _ := ctx.Get("sessionID")
+	sessions, err := uc.sessionUsecase.GetSessions(userID.(string), sessionID.(string))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+func (uc *UserController) RevokeSession(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	if err := uc.sessionUsecase.RevokeSession(userID.(string), ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "session revoked successfully"})
 }
 
 func (uc *UserController) ForgetPassword(ctx *gin.Context) {
@@ -120,7 +300,44 @@ func (uc *UserController) GetProfile(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, user)
+	ctx.JSON(http.StatusOK, domain.NewUserResponse(user))
+}
+
+func (uc *UserController) UpdateProfile(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	input := struct {
+		Name     *string `json:"name"`
+		Phone    *string `json:"phone"`
+		Address  *string `json:"address"`
+		Language *string `json:"language"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	update := domain.UserUpdate{Name: input.Name, Phone: input.Phone, Address: input.Address, Language: input.Language}
+	user, err := uc.userUsecase.UpdateProfile(userID.(string), update)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.NewUserResponse(user))
+}
+
+func (uc *UserController) DeleteAccount(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	input := struct {
+		Password string `json:"password" binding:"required"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "password field is required"})
+		return
+	}
+	if err := uc.userUsecase.DeleteAccount(userID.(string), input.Password); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "account deleted, your personal data has been anonymized"})
 }
 
 func (uc *UserController) GetUserByID(ctx *gin.Context) {
@@ -130,7 +347,7 @@ func (uc *UserController) GetUserByID(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, user)
+	ctx.JSON(http.StatusOK, domain.NewUserResponse(user))
 }
 
 func (uc *UserController) GetAllUsers(ctx *gin.Context) {
@@ -139,5 +356,125 @@ func (uc *UserController) GetAllUsers(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, users)
+	response := make([]domain.UserResponse, len(users))
+	for i, user := range users {
+		response[i] = domain.NewUserResponse(user)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (uc *UserController) AssignRoles(ctx *gin.Context) {
+	input := struct {
+		Roles []domain.Role `json:"roles"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	userID := ctx.Param("id")
+	// Granting roles may need a second admin; removing them never does.
+	request := domain.ActionRequest{Type: domain.ActionRoleGrant, TargetID: userID, Roles: input.Roles}
+	if !performAction(ctx, uc.actionRequestUsecase, request) {
+		return
+	}
+	user, err := uc.userUsecase.GetUserByID(userID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.NewUserResponse(user))
+}
+
+// RevokeAdmin removes every role from a user, revoking their admin access.
+func (uc *UserController) RevokeAdmin(ctx *gin.Context) {
+	userID := ctx.Param("id")
+	request := domain.ActionRequest{Type: domain.ActionRoleGrant, TargetID: userID, Roles: []domain.Role{}}
+	if !performAction(ctx, uc.actionRequestUsecase, request) {
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "admin access revoked"})
+}
+
+func (uc *UserController) ActivateUser(ctx *gin.Context) {
+	uc.setUserActive(ctx, true)
+}
+
+func (uc *UserController) DeactivateUser(ctx *gin.Context) {
+	uc.setUserActive(ctx, false)
+}
+
+func (uc *UserController) setUserActive(ctx *gin.Context, active bool) {
+	actorID, _ := ctx.Get("userID")
+	user, err := uc.userUsecase.SetUserActive(actorID.(string), ctx.Param("id"), active)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.NewUserResponse(user))
+}
+
+func (uc *UserController) ForcePasswordReset(ctx *gin.Context) {
+	actorID, _ := ctx.Get("userID")
+	if err := uc.userUsecase.ForcePasswordReset(actorID.(string), ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "password reset link sent to the user"})
+}
+
+func (uc *UserController) DeleteUser(ctx *gin.Context) {
+	actorID, _ := ctx.Get("userID")
+	if err := uc.userUsecase.DeleteUser(actorID.(string), ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
+}
+
+func (uc *UserController) ChangePassword(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	sessionID, _ := ctx.Get("sessionID")
+	input := struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		Password        string `json:"password" binding:"required"`
+		ConfirmPassword string `json:"confirm_password" binding:"required"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	if input.Password != input.ConfirmPassword {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "password and confirm password don't match"})
+		return
+	}
+	if err := uc.userUsecase.ChangePassword(userID.(string), sessionID.(string), input.CurrentPassword, input.Password); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "password changed, other sessions have been signed out"})
+}
+
+func (uc *UserController) RequestEmailChange(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	input := struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "invalid data format"})
+		return
+	}
+	if err := uc.userUsecase.RequestEmailChange(userID.(string), input.Email, input.Password); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "confirmation link sent to the new email address"})
+}
+
+func (uc *UserController) ConfirmEmailChange(ctx *gin.Context) {
+	if _, err := uc.userUsecase.ConfirmEmailChange(ctx.Query("token")); err != nil {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "email changed successfully, please log in again"})
 }
@@ -14,6 +14,13 @@ func AdminMiddleware() gin.HandlerFunc {
 			ctx.Abort()
 			return
 		}
+		// Admins must have signed in with their second factor.
+		mfa, _ := ctx.Get("mfa")
+		if mfa != true {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "multi-factor authentication is required for admin access"})
+			ctx.Abort()
+			return
+		}
 		ctx.Next()
 	}
 }
@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"errors"
+	"loan-management/internal/domain"
+	"loan-management/internal/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+// APIKeyHeader carries the API key of machine-to-machine requests.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyMiddleware authenticates a request by its API key, which must grant
+// the scope. The request then acts as the key's owner; every call is counted
+// against the key's rate limit and recorded in the audit log.
+func APIKeyMiddleware(db mongoifc.Database, scope domain.APIScope) gin.HandlerFunc {
+	apiKeyUsecase := usecases.NewAPIKeyUsecase(db)
+	return func(ctx *gin.Context) {
+		secret := ctx.GetHeader(APIKeyHeader)
+		if secret == "" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "api key is missing"})
+			ctx.Abort()
+			return
+		}
+		key, err := apiKeyUsecase.Authenticate(secret, ctx.ClientIP())
+		if errors.Is(err, usecases.ErrAPIKeyRateLimited) {
+			ctx.Header("Retry-After", "60")
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			ctx.Abort()
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			ctx.Abort()
+			return
+		}
+		if !key.HasScope(scope) {
+			apiKeyUsecase.RecordUse(key, ctx.Request.Method, ctx.FullPath(), ctx.ClientIP(), http.StatusForbidden)
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "scope " + string(scope) + " required"})
+			ctx.Abort()
+			return
+		}
+		ctx.Set("userID", key.UserID)
+		ctx.Set("isAdmin", false)
+		ctx.Set("apiKeyID", key.ID)
+		ctx.Set("scopes", key.Scopes)
+
+		ctx.Next()
+		apiKeyUsecase.RecordUse(key, ctx.Request.Method, ctx.FullPath(), ctx.ClientIP(), ctx.Writer.Status())
+	}
+}
+
+// APIKeyOrJWTMiddleware accepts either an API key granting the scope or a
+// user's access token, for endpoints open to both partners and users.
+func APIKeyOrJWTMiddleware(db mongoifc.Database, scope domain.APIScope) gin.HandlerFunc {
+	apiKeyMiddleware := APIKeyMiddleware(db, scope)
+	jwtMiddleware := JWTMiddleware(db)
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader(APIKeyHeader) != "" {
+			apiKeyMiddleware(ctx)
+			return
+		}
+		jwtMiddleware(ctx)
+	}
+}
@@ -1,14 +1,19 @@
 package middlewares
 
 import (
-	"loan-management/pkg/infrastructures"
+	"loan-management/internal/usecases"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
 )
 
-func JWTMiddleware() gin.HandlerFunc {
+// JWTMiddleware authenticates the bearer access token and rejects tokens whose
+// session has been logged out or revoked, even before they expire.
+func JWTMiddleware(db mongoifc.Database) gin.HandlerFunc {
+	sessionUsecase := usecases.NewSessionUsecase(db)
+	signingKeyUsecase := usecases.NewSigningKeyUsecase(db)
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -23,15 +28,23 @@ func JWTMiddleware() gin.HandlerFunc {
 			return
 		}
 		tokenString := header[1]
-		claims, err := infrastructures.ValidateJWTToken(tokenString)
+		claims, err := signingKeyUsecase.VerifyAccessToken(tokenString)
 		if err != nil {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			ctx.Abort()
 			return
 		}
+		if err := sessionUsecase.ValidateSession(claims.SessionID, claims.UserID); err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			ctx.Abort()
+			return
+		}
 		ctx.Set("userID", claims.UserID)
 		ctx.Set("email", claims.Email)
 		ctx.Set("isAdmin", claims.IsAdmin)
+		ctx.Set("sessionID", claims.SessionID)
+		ctx.Set("mfa", claims.MFA)
+		ctx.Set("roles", claims.Roles)
 
 		ctx.Next()
 	}
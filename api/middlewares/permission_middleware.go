@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"loan-management/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission allows the request only if the roles in the access token
+// grant every listed permission. It must run after JWTMiddleware.
+func RequirePermission(permissions ...domain.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, _ := ctx.Get("roles")
+		roles, _ := value.([]domain.Role)
+		for _, p := range permissions {
+			if !domain.HasPermission(roles, p) {
+				ctx.JSON(http.StatusForbidden, gin.H{"error": "permission " + string(p) + " required"})
+				ctx.Abort()
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"loan-management/api/controllers"
+	"loan-management/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+// AddActionRequestRoutes registers the dual-control queue. Approving checks
+// the permission of the specific action in the usecase.
+func AddActionRequestRoutes(r *gin.Engine, db mongoifc.Database) {
+	actionRequestController := controllers.NewActionRequestController(db)
+	adminRouter := r.Group("/admin/action-requests")
+	adminRouter.Use(middlewares.JWTMiddleware(db))
+	adminRouter.Use(middlewares.AdminMiddleware())
+	{
+		adminRouter.GET("/", actionRequestController.GetActionRequests)
+		adminRouter.GET("/:id", actionRequestController.GetActionRequest)
+		adminRouter.POST("/:id/approve", actionRequestController.ApproveActionRequest)
+		adminRouter.POST("/:id/reject", actionRequestController.RejectActionRequest)
+	}
+}
@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"loan-management/api/controllers"
+	"loan-management/api/middlewares"
+	"loan-management/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+func AddAPIKeyRoutes(r *gin.Engine, db mongoifc.Database) {
+	apiKeyController := controllers.NewAPIKeyController(db)
+	adminRouter := r.Group("/admin/api-keys")
+	adminRouter.Use(middlewares.JWTMiddleware(db))
+	adminRouter.Use(middlewares.AdminMiddleware())
+	adminRouter.Use(middlewares.RequirePermission(domain.PermissionAPIKeysManage))
+	{
+		adminRouter.POST("/", apiKeyController.CreateAPIKey)
+		adminRouter.GET("/", apiKeyController.GetAPIKeys)
+		adminRouter.DELETE("/:id", apiKeyController.RevokeAPIKey)
+	}
+}
@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"loan-management/api/controllers"
+	"loan-management/api/middlewares"
+	"loan-management/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+// AddEmailOutboxRoutes registers the dead-letter view of emails that could
+// not be delivered. Listing needs logs:read; requeueing needs users:manage.
+func AddEmailOutboxRoutes(r *gin.Engine, db mongoifc.Database) {
+	emailOutboxController := controllers.NewEmailOutboxController(db)
+	adminRouter := r.Group("/admin/email-outbox")
+	adminRouter.Use(middlewares.JWTMiddleware(db))
+	adminRouter.Use(middlewares.AdminMiddleware())
+	{
+		adminRouter.GET("/dead-letters", middlewares.RequirePermission(domain.PermissionLogsRead), emailOutboxController.GetDeadLetters)
+		adminRouter.POST("/dead-letters/:id/retry", middlewares.RequirePermission(domain.PermissionUsersManage), emailOutboxController.RetryDeadLetter)
+	}
+}
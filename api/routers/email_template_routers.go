@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"loan-management/api/controllers"
+	"loan-management/api/middlewares"
+	"loan-management/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+func AddEmailTemplateRoutes(r *gin.Engine, db mongoifc.Database) {
+	emailTemplateController := controllers.NewEmailTemplateController()
+	adminRouter := r.Group("/admin/email-templates")
+	adminRouter.Use(middlewares.JWTMiddleware(db))
+	adminRouter.Use(middlewares.AdminMiddleware())
+	adminRouter.Use(middlewares.RequirePermission(domain.PermissionUsersManage))
+	{
+		adminRouter.GET("/", emailTemplateController.GetEmailTemplates)
+		adminRouter.GET("/:name/preview", emailTemplateController.PreviewEmailTemplate)
+	}
+}
@@ -3,6 +3,7 @@ package routers
 import (
 	"loan-management/api/controllers"
 	"loan-management/api/middlewares"
+	"loan-management/internal/domain"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sv-tools/mongoifc"
@@ -10,18 +11,33 @@ import (
 
 func AddLoanRoutes(r *gin.Engine, db mongoifc.Database) {
 	loanController := controllers.NewLoanController(db)
+	collateralController := controllers.NewCollateralController(db)
 	loanRouter := r.Group("/loans")
-	loanRouter.Use(middlewares.JWTMiddleware())
 	{
-		loanRouter.POST("/", loanController.CreateLoan)
-		loanRouter.GET("/:id", loanController.ViewLoanStatus)
+		loanRouter.POST("/", middlewares.APIKeyOrJWTMiddleware(db, domain.APIScopeLoansSubmit), loanController.CreateLoan)
+		loanRouter.GET("/:id", middlewares.APIKeyOrJWTMiddleware(db, domain.APIScopeLoansRead), loanController.ViewLoanStatus)
+		loanRouter.POST("/:id/offer/accept", middlewares.JWTMiddleware(db), loanController.AcceptOffer)
+		loanRouter.POST("/:id/offer/decline", middlewares.JWTMiddleware(db), loanController.DeclineOffer)
 	}
 	adminRouter := r.Group("/admin/loans")
-	adminRouter.Use(middlewares.JWTMiddleware())
+	adminRouter.Use(middlewares.JWTMiddleware(db))
 	adminRouter.Use(middlewares.AdminMiddleware())
 	{
-		adminRouter.GET("/", loanController.ViewAllLoans)
-		adminRouter.PATCH("/:id/:status", loanController.ApproveRejectLoan)
-		adminRouter.DELETE("/:id", loanController.DeleteLoan)
+		adminRouter.GET("/", middlewares.RequirePermission(domain.PermissionLoansRead), loanController.ViewAllLoans)
+		adminRouter.PATCH("/:id/:status", middlewares.RequirePermission(domain.PermissionLoansApprove), loanController.ApproveRejectLoan)
+		adminRouter.DELETE("/:id", middlewares.RequirePermission(domain.PermissionLoansDelete), loanController.DeleteLoan)
+		adminRouter.POST("/:id/write-off", middlewares.RequirePermission(domain.PermissionLoansWriteOff), loanController.WriteOffLoan)
+		adminRouter.POST("/:id/collaterals", middlewares.RequirePermission(domain.PermissionCollateralManage), collateralController.AddCollateral)
+		adminRouter.GET("/:id/collaterals", middlewares.RequirePermission(domain.PermissionLoansRead), collateralController.GetLoanCollaterals)
+		adminRouter.GET("/:id/accruals", middlewares.RequirePermission(domain.PermissionLoansRead), loanController.ViewLoanAccruals)
+		adminRouter.POST("/:id/installments/:number/payment", middlewares.RequirePermission(domain.PermissionPaymentsRecord), loanController.RecordPayment)
+	}
+	collateralRouter := r.Group("/admin/collaterals")
+	collateralRouter.Use(middlewares.JWTMiddleware(db))
+	collateralRouter.Use(middlewares.AdminMiddleware())
+	{
+		collateralRouter.GET("/:id", middlewares.RequirePermission(domain.PermissionLoansRead), collateralController.GetCollateral)
+		collateralRouter.PATCH("/:id", middlewares.RequirePermission(domain.PermissionCollateralManage), collateralController.UpdateCollateral)
+		collateralRouter.DELETE("/:id", middlewares.RequirePermission(domain.PermissionCollateralManage), collateralController.DeleteCollateral)
 	}
 }
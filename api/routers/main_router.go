@@ -5,6 +5,9 @@ import (
 	"loan-management/api/middlewares"
 	"loan-management/config"
 	"loan-management/database"
+	"loan-management/internal/domain"
+	"loan-management/internal/jobs"
+	"loan-management/pkg/infrastructures"
 	"log"
 
 	"github.com/gin-gonic/gin"
@@ -19,10 +22,23 @@ func Run() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	mailer, err := infrastructures.NewMailer(config.Email)
+	if err != nil {
+		log.Fatal(err)
+	}
+	jobs.Start(config, db, mailer)
 	router := gin.Default()
 	logController := controllers.NewLogController(db)
-	router.GET("/admin/logs", middlewares.JWTMiddleware(), middlewares.AdminMiddleware(), logController.GetLogs)
+	jwksController := controllers.NewJWKSController(db)
+	router.GET("/.well-known/jwks.json", jwksController.GetJWKS)
+	router.GET("/admin/logs", middlewares.JWTMiddleware(db), middlewares.AdminMiddleware(), middlewares.RequirePermission(domain.PermissionLogsRead), logController.GetLogs)
 	AddUserRoutes(router, db)
 	AddLoanRoutes(router, db)
+	AddProductRoutes(router, db)
+	AddActionRequestRoutes(router, db)
+	AddAPIKeyRoutes(router, db)
+	AddEmailTemplateRoutes(router, db)
+	AddEmailOutboxRoutes(router, db)
+	AddNotificationRoutes(router, db)
 	router.Run(config.Server.Port)
 }
@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"loan-management/api/controllers"
+	"loan-management/api/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+func AddNotificationRoutes(r *gin.Engine, db mongoifc.Database) {
+	notificationController := controllers.NewNotificationController(db)
+	notificationRouter := r.Group("/users/notifications")
+	notificationRouter.Use(middlewares.JWTMiddleware(db))
+	{
+		notificationRouter.GET("", notificationController.GetNotifications)
+		notificationRouter.POST("/:id/read", notificationController.MarkRead)
+		notificationRouter.GET("/preferences", notificationController.GetPreferences)
+		notificationRouter.PUT("/preferences", notificationController.UpdatePreferences)
+	}
+}
@@ -0,0 +1,32 @@
+package routers
+
+import (
+	"loan-management/api/controllers"
+	"loan-management/api/middlewares"
+	"loan-management/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sv-tools/mongoifc"
+)
+
+func AddProductRoutes(r *gin.Engine, db mongoifc.Database) {
+	productController := controllers.NewProductController(db)
+	productRouter := r.Group("/products")
+	productRouter.Use(middlewares.JWTMiddleware(db))
+	{
+		productRouter.GET("/", productController.GetProducts)
+		productRouter.GET("/:id", productController.GetProduct)
+	}
+	adminRouter := r.Group("/admin")
+	adminRouter.Use(middlewares.JWTMiddleware(db))
+	adminRouter.Use(middlewares.AdminMiddleware())
+	adminRouter.Use(middlewares.RequirePermission(domain.PermissionProductsManage))
+	{
+		adminRouter.POST("/products", productController.CreateProduct)
+		adminRouter.PATCH("/products/:id", productController.UpdateProduct)
+		adminRouter.DELETE("/products/:id", productController.DeleteProduct)
+		adminRouter.GET("/benchmark-rates", productController.GetBenchmarkRates)
+		adminRouter.POST("/benchmark-rates", productController.AddBenchmarkRate)
+		adminRouter.DELETE("/benchmark-rates/:id", productController.DeleteBenchmarkRate)
+	}
+}
@@ -3,6 +3,7 @@ package routers
 import (
 	"loan-management/api/controllers"
 	"loan-management/api/middlewares"
+	"loan-management/internal/domain"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sv-tools/mongoifc"
@@ -14,17 +15,39 @@ func AddUserRoutes(r *gin.Engine, db mongoifc.Database) {
 	{
 		userRouteGroup.POST("/register", userController.SignUp)
 		userRouteGroup.GET("/verify-email", userController.VerifyEmail)
+		userRouteGroup.POST("/verify-email/resend", userController.ResendVerification)
 		userRouteGroup.POST("/login", userController.Login)
+		userRouteGroup.POST("/login/mfa", userController.VerifyMFA)
+		userRouteGroup.GET("/login/oidc", userController.StartOIDCLogin)
+		userRouteGroup.GET("/login/oidc/callback", userController.OIDCCallback)
+		userRouteGroup.GET("/unlock", userController.UnlockAccount)
 		userRouteGroup.POST("/password-reset", userController.ForgetPassword)
 		userRouteGroup.POST("/password-update", userController.ResetPassword)
 		userRouteGroup.POST("/token/refresh", userController.RefreshAccessToken)
-		userRouteGroup.GET("/profile", middlewares.JWTMiddleware(), userController.GetProfile)
+		userRouteGroup.GET("/email/confirm", userController.ConfirmEmailChange)
+		userRouteGroup.GET("/profile", middlewares.JWTMiddleware(db), userController.GetProfile)
+		userRouteGroup.PATCH("/profile", middlewares.JWTMiddleware(db), userController.UpdateProfile)
+		userRouteGroup.DELETE("/profile", middlewares.JWTMiddleware(db), userController.DeleteAccount)
+		userRouteGroup.POST("/password", middlewares.JWTMiddleware(db), userController.ChangePassword)
+		userRouteGroup.POST("/email", middlewares.JWTMiddleware(db), userController.RequestEmailChange)
+		userRouteGroup.POST("/logout", middlewares.JWTMiddleware(db), userController.Logout)
+		userRouteGroup.GET("/sessions", middlewares.JWTMiddleware(db), userController.GetSessions)
+		userRouteGroup.DELETE("/sessions/:id", middlewares.JWTMiddleware(db), userController.RevokeSession)
+		userRouteGroup.POST("/mfa/enroll", middlewares.JWTMiddleware(db), userController.EnrollMFA)
+		userRouteGroup.POST("/mfa/confirm", middlewares.JWTMiddleware(db), userController.ConfirmMFA)
+		userRouteGroup.POST("/mfa/disable", middlewares.JWTMiddleware(db), userController.DisableMFA)
 	}
 	adminRoutes := r.Group("/admin")
-	adminRoutes.Use(middlewares.JWTMiddleware())
+	adminRoutes.Use(middlewares.JWTMiddleware(db))
 	adminRoutes.Use(middlewares.AdminMiddleware())
 	{
-		adminRoutes.GET("/users", userController.GetAllUsers)
-		adminRoutes.GET("/users/:id", userController.GetUserByID)
+		adminRoutes.GET("/users", middlewares.RequirePermission(domain.PermissionUsersRead), userController.GetAllUsers)
+		adminRoutes.GET("/users/:id", middlewares.RequirePermission(domain.PermissionUsersRead), userController.GetUserByID)
+		adminRoutes.PUT("/users/:id/roles", middlewares.RequirePermission(domain.PermissionRolesAssign), userController.AssignRoles)
+		adminRoutes.DELETE("/users/:id/roles", middlewares.RequirePermission(domain.PermissionRolesAssign), userController.RevokeAdmin)
+		adminRoutes.POST("/users/:id/activate", middlewares.RequirePermission(domain.PermissionUsersManage), userController.ActivateUser)
+		adminRoutes.POST("/users/:id/deactivate", middlewares.RequirePermission(domain.PermissionUsersManage), userController.DeactivateUser)
+		adminRoutes.POST("/users/:id/password-reset", middlewares.RequirePermission(domain.PermissionUsersManage), userController.ForcePasswordReset)
+		adminRoutes.DELETE("/users/:id", middlewares.RequirePermission(domain.PermissionUsersManage), userController.DeleteUser)
 	}
 }
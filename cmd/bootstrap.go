@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"loan-management/config"
+	"loan-management/database"
+	"loan-management/internal/domain"
+	"loan-management/internal/usecases"
+	"os"
+	"strings"
+
+	"github.com/sv-tools/mongoifc"
+)
+
+// bootstrap creates the initial super admin and optionally seeds reference
+// data. The password is read from BOOTSTRAP_ADMIN_PASSWORD or standard input
+// so it does not end up in the shell history.
+func bootstrap(args []string) error {
+	fs := flag.NewFlagSet("bootstrap", flag.ContinueOnError)
+	email := fs.String("email", "", "email of the super admin")
+	name := fs.String("name", "", "name of the super admin")
+	seedFile := fs.String("seed", "", "optional JSON file of reference data to seed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+	password := os.Getenv("BOOTSTRAP_ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Print("Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("reading password: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	user, err := usecases.NewBootstrapUsecase(db).CreateSuperAdmin(*email, *name, password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created super admin %s (%s). Enroll MFA after logging in to use admin endpoints.\n", user.Email, user.ID)
+
+	if *seedFile != "" {
+		return seedFrom(db, *seedFile)
+	}
+	return nil
+}
+
+// seed loads reference data such as loan products and benchmark rates.
+func seed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	file := fs.String("file", "", "JSON file of reference data")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	return seedFrom(db, *file)
+}
+
+func seedFrom(db mongoifc.Database, file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var data domain.ReferenceData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return fmt.Errorf("parsing %s: %v", file, err)
+	}
+	created, err := usecases.NewBootstrapUsecase(db).SeedReferenceData(data)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Seeded %d reference records.\n", created)
+	return nil
+}
+
+func openDatabase() (mongoifc.Database, error) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return database.NewMongoDatabase(config)
+}
@@ -1,7 +1,29 @@
 package main
 
-import "loan-management/api/routers"
+import (
+	"fmt"
+	"loan-management/api/routers"
+	"os"
+)
 
 func main() {
-	routers.Run()
+	if len(os.Args) < 2 || os.Args[1] == "serve" {
+		routers.Run()
+		return
+	}
+	var err error
+	switch os.Args[1] {
+	case "bootstrap":
+		err = bootstrap(os.Args[2:])
+	case "seed":
+		err = seed(os.Args[2:])
+	case "mock-idp":
+		err = mockIdPCommand(os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown command %q, expected serve, bootstrap, seed or mock-idp", os.Args[1])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"loan-management/pkg/infrastructures"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIdP is a minimal OpenID Connect provider for trying out single sign-on
+// locally. Every authorization request is approved immediately for the user
+// given on the command line.
+type mockIdP struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	claims       jwt.MapClaims
+	key          *rsa.PrivateKey
+	kid          string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+	expiresAt   time.Time
+}
+
+// mockIdPCommand runs the mock provider until interrupted.
+func mockIdPCommand(args []string) error {
+	fs := flag.NewFlagSet("mock-idp", flag.ContinueOnError)
+	addr := fs.String("addr", ":9000", "address to listen on")
+	issuer := fs.String("issuer", "http://localhost:9000", "issuer URL, must match oidc.issuer")
+	clientID := fs.String("client-id", "loan-management", "accepted client ID")
+	clientSecret := fs.String("client-secret", "", "client secret; empty accepts public clients")
+	subject := fs.String("sub", "mock-user-1", "subject of the logged in user")
+	email := fs.String("email", "officer@example.com", "email of the logged in user")
+	name := fs.String("name", "Mock Officer", "name of the logged in user")
+	groups := fs.String("groups", "loan-officers", "comma separated groups of the logged in user")
+	amr := fs.String("amr", "pwd,mfa", "comma separated authentication methods")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	idp := &mockIdP{
+		issuer:       strings.TrimSuffix(*issuer, "/"),
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		claims: jwt.MapClaims{
+			"sub":            *subject,
+			"email":          *email,
+			"email_verified": true,
+			"name":           *name,
+			"groups":         splitList(*groups),
+			"amr":            splitList(*amr),
+		},
+		key:   key,
+		kid:   "mock-" + time.Now().Format("20060102150405"),
+		codes: map[string]mockAuthorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+	fmt.Printf("Mock identity provider %s listening on %s, logging in %s with groups %s\n", idp.issuer, *addr, *email, *groups)
+	return http.ListenAndServe(*addr, mux)
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                idp.issuer,
+		"authorization_endpoint":                idp.issuer + "/authorize",
+		"token_endpoint":                        idp.issuer + "/token",
+		"jwks_uri":                              idp.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" || q.Get("client_id") != idp.clientID ||
+		q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "expected a code request for the configured client with an S256 code challenge", http.StatusBadRequest)
+		return
+	}
+	code, _, err := infrastructures.GenerateOpaqueToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	idp.mu.Lock()
+	idp.codes[code] = mockAuthorization{
+		redirectURI: redirectURI.String(),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		expiresAt:   time.Now().Add(time.Minute),
+	}
+	idp.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", q.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if user, secret, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(user)
+		secret, _ = url.QueryUnescape(secret)
+		if idp.clientSecret != "" && secret != idp.clientSecret {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+	} else if idp.clientSecret != "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if clientID != idp.clientID {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	idp.mu.Lock()
+	authorization, ok := idp.codes[code]
+	delete(idp.codes, code)
+	idp.mu.Unlock()
+	if !ok || time.Now().After(authorization.expiresAt) ||
+		authorization.redirectURI != r.PostForm.Get("redirect_uri") ||
+		!infrastructures.VerifyPKCE(r.PostForm.Get("code_verifier"), authorization.challenge) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   idp.issuer,
+		"aud":   idp.clientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": authorization.nonce,
+	}
+	for name, value := range idp.claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.kid
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	jwk, err := infrastructures.PublicJWK(idp.kid, infrastructures.AlgorithmRS256, &idp.key.PublicKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []infrastructures.JSONWebKey{jwk}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
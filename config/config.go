@@ -11,19 +11,138 @@ type Database struct {
 type Email struct {
 	Key     string `mapstructure:"key"`
 	Address string `mapstructure:"address"`
+	// TemplatesDir holds the email templates, one directory per locale plus
+	// shared layouts. Defaults to templates/email.
+	TemplatesDir string `mapstructure:"templates_dir"`
+	// Transport is smtp (the default), file, console or memory.
+	Transport string `mapstructure:"transport"`
+	// OutputDir is where the file transport writes .eml files.
+	OutputDir string `mapstructure:"output_dir"`
+	SMTP      SMTP   `mapstructure:"smtp"`
+}
+
+// SMTP configures the SMTP transport. TLS is starttls (the default), tls for
+// implicit TLS or none; Auth is plain (the default), crammd5 or none. The
+// username and password default to the email address and key.
+type SMTP struct {
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	TLS      string `mapstructure:"tls"`
+	Auth     string `mapstructure:"auth"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
 }
 type Jwt struct {
-	Secret string `mapstructure:"secret"`
+	Secret          string `mapstructure:"secret"`
+	AccessTokenTTL  string `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL string `mapstructure:"refresh_token_ttl"`
+	// Algorithm is RS256 or EdDSA; access tokens are signed with rotating
+	// key pairs published at /.well-known/jwks.json.
+	Algorithm           string `mapstructure:"algorithm"`
+	KeyRotationInterval string `mapstructure:"key_rotation_interval"`
+	Issuer              string `mapstructure:"issuer"`
+	Audience            string `mapstructure:"audience"`
+	ClockSkew           string `mapstructure:"clock_skew"`
 }
 type Server struct {
 	Port string `mapstructure:"port"`
 	Url  string `mapstructure:"url"`
 }
+type Loan struct {
+	MaxLTV              float64 `mapstructure:"max_ltv"`
+	InterestRate        float64 `mapstructure:"interest_rate"`
+	DayCountConvention  string  `mapstructure:"day_count_convention"`
+	TermMonths          int     `mapstructure:"term_months"`
+	MaxTermMonths       int     `mapstructure:"max_term_months"`
+	OriginationFeeRate  float64 `mapstructure:"origination_fee_rate"`
+	AccrualInterval     string  `mapstructure:"accrual_interval"`
+	RateResetInterval   string  `mapstructure:"rate_reset_interval"`
+	OfferValidity       string  `mapstructure:"offer_validity"`
+	OfferExpiryInterval string  `mapstructure:"offer_expiry_interval"`
+}
+
+// Approval configures dual control: matching admin actions need a second
+// admin to approve them before they run.
+type Approval struct {
+	// LoanAmountThreshold is the amount above which approving a loan needs a
+	// second admin; 0 disables dual control for approvals.
+	LoanAmountThreshold float64 `mapstructure:"loan_amount_threshold"`
+	WriteOffs           bool    `mapstructure:"write_offs"`
+	LoanDeletions       bool    `mapstructure:"loan_deletions"`
+	RoleGrants          bool    `mapstructure:"role_grants"`
+	RequestValidity     string  `mapstructure:"request_validity"`
+}
+
+// Lockout configures brute-force protection on login. After each failure the
+// next attempt is delayed (Delay, doubling per failure); reaching the maximum
+// number of failures within Window locks the account or IP for Duration.
+type Lockout struct {
+	MaxAccountAttempts int    `mapstructure:"max_account_attempts"`
+	MaxIPAttempts      int    `mapstructure:"max_ip_attempts"`
+	Window             string `mapstructure:"window"`
+	Duration           string `mapstructure:"duration"`
+	Delay              string `mapstructure:"delay"`
+}
+
+// Password configures the password policy. BreachedHashesFile is an optional
+// sorted "SHA1:COUNT" list of breached password hashes.
+type Password struct {
+	MinLength          int    `mapstructure:"min_length"`
+	RequireUpper       bool   `mapstructure:"require_upper"`
+	RequireLower       bool   `mapstructure:"require_lower"`
+	RequireDigit       bool   `mapstructure:"require_digit"`
+	RequireSymbol      bool   `mapstructure:"require_symbol"`
+	BreachedHashesFile string `mapstructure:"breached_hashes_file"`
+	HistorySize        int    `mapstructure:"history_size"`
+}
+
+// OIDC configures single sign-on for staff through an OpenID Connect
+// identity provider. GroupRoles maps IdP group names to internal roles;
+// users whose groups map to no role cannot log in this way.
+type OIDC struct {
+	Enabled      bool                `mapstructure:"enabled"`
+	Issuer       string              `mapstructure:"issuer"`
+	ClientID     string              `mapstructure:"client_id"`
+	ClientSecret string              `mapstructure:"client_secret"`
+	RedirectURL  string              `mapstructure:"redirect_url"`
+	Scopes       []string            `mapstructure:"scopes"`
+	GroupsClaim  string              `mapstructure:"groups_claim"`
+	GroupRoles   map[string][]string `mapstructure:"group_roles"`
+	// TrustMFA treats every IdP login as having passed a second factor.
+	// Otherwise only ID tokens whose amr claim reports one do.
+	TrustMFA bool `mapstructure:"trust_mfa"`
+}
+
+// Outbox configures delivery of queued emails. The worker polls every
+// Interval; a failed email is retried after RetryDelay, doubling per attempt
+// up to MaxRetryDelay, and moved to the dead letters after MaxAttempts.
+type Outbox struct {
+	Interval      string `mapstructure:"interval"`
+	MaxAttempts   int    `mapstructure:"max_attempts"`
+	RetryDelay    string `mapstructure:"retry_delay"`
+	MaxRetryDelay string `mapstructure:"max_retry_delay"`
+}
+
+// Notifications configures borrower notifications. The payment notice job
+// runs every Interval and reminds borrowers PaymentReminderDays before an
+// installment is due.
+type Notifications struct {
+	Interval            string `mapstructure:"interval"`
+	PaymentReminderDays int    `mapstructure:"payment_reminder_days"`
+}
+
 type Config struct {
-	Database Database `mapstructure:"database"`
-	Server   Server   `mapstructure:"server"`
-	Email    Email    `mapstructure:"email"`
-	Jwt      Jwt      `mapstructure:"jwt"`
+	Database      Database      `mapstructure:"database"`
+	Server        Server        `mapstructure:"server"`
+	Email         Email         `mapstructure:"email"`
+	Jwt           Jwt           `mapstructure:"jwt"`
+	Loan          Loan          `mapstructure:"loan"`
+	Approval      Approval      `mapstructure:"approval"`
+	Lockout       Lockout       `mapstructure:"lockout"`
+	Password      Password      `mapstructure:"password"`
+	OIDC          OIDC          `mapstructure:"oidc"`
+	Outbox        Outbox        `mapstructure:"outbox"`
+	Notifications Notifications `mapstructure:"notifications"`
 }
 
 func LoadConfig() (Config, error) {
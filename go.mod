@@ -3,8 +3,8 @@ module loan-management
 go 1.23.0
 
 require (
-	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/gin-gonic/gin v1.10.0
+	github.com/golang-jwt/jwt/v5 v5.3.1
 	github.com/spf13/viper v1.19.0
 	github.com/sv-tools/mongoifc v1.16.1
 	go.mongodb.org/mongo-driver v1.16.1
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+const InterestAccrualCollection = "interest_accruals"
+
+// Day-count conventions supported by the accrual engine.
+const (
+	DayCountActual365 = "actual/365"
+	DayCountActual360 = "actual/360"
+	DayCount30360     = "30/360"
+)
+
+// InterestAccrual is the interest earned by a loan over a single day.
+type InterestAccrual struct {
+	ID                 string    `json:"id" bson:"_id"`
+	LoanID             string    `json:"loan_id" bson:"loan_id"`
+	Date               time.Time `json:"date" bson:"date"`
+	Principal          float64   `json:"principal" bson:"principal"`
+	InterestRate       float64   `json:"interest_rate" bson:"interest_rate"`
+	DayCountConvention string    `json:"day_count_convention" bson:"day_count_convention"`
+	Amount             float64   `json:"amount" bson:"amount"`
+	CreatedAt          time.Time `json:"created_at" bson:"created_at"`
+}
+
+type InterestAccrualRepository interface {
+	Create(InterestAccrual) (InterestAccrual, error)
+	GetByLoanID(string) ([]InterestAccrual, error)
+}
+
+type InterestAccrualUsecase interface {
+	AccrueInterest(asOf time.Time) (int, error)
+	GetLoanAccruals(loanID string) ([]InterestAccrual, error)
+}
@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+const ActionRequestCollection = "action_requests"
+
+// Sensitive admin actions that can be placed under dual control.
+const (
+	ActionLoanApproval = "loan_approval"
+	ActionLoanWriteOff = "loan_write_off"
+	ActionLoanDeletion = "loan_deletion"
+	ActionRoleGrant    = "role_grant"
+)
+
+// Action request statuses. A pending request is executed once a second admin
+// approves it; approval and execution are recorded separately so a failed
+// execution is still attributed to both admins.
+const (
+	ActionStatusPending  = "pending"
+	ActionStatusApproved = "approved"
+	ActionStatusRejected = "rejected"
+	ActionStatusExecuted = "executed"
+	ActionStatusFailed   = "failed"
+	ActionStatusExpired  = "expired"
+)
+
+// DefaultActionRequestValidity is how long a pending request can be approved
+// when not configured.
+const DefaultActionRequestValidity = 72 * time.Hour
+
+// ActionRequest is a sensitive admin action. The maker files it and, when
+// dual control applies, a different admin (the checker) must approve it
+// before it runs.
+type ActionRequest struct {
+	ID          string    `json:"id" bson:"_id"`
+	Type        string    `json:"type" bson:"type"`
+	TargetID    string    `json:"target_id" bson:"target_id"`
+	Roles       []Role    `json:"roles,omitempty" bson:"roles,omitempty"`
+	Reason      string    `json:"reason" bson:"reason"`
+	Status      string    `json:"status" bson:"status"`
+	DualControl bool      `json:"dual_control" bson:"dual_control"`
+	RequestedBy string    `json:"requested_by" bson:"requested_by"`
+	RequestedAt time.Time `json:"requested_at" bson:"requested_at"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at"`
+	ReviewedBy  string    `json:"reviewed_by,omitempty" bson:"reviewed_by"`
+	ReviewedAt  time.Time `json:"reviewed_at" bson:"reviewed_at"`
+	ReviewNote  string    `json:"review_note,omitempty" bson:"review_note"`
+	ExecutedAt  time.Time `json:"executed_at" bson:"executed_at"`
+	Error       string    `json:"error,omitempty" bson:"error"`
+}
+
+type ActionRequestRepository interface {
+	Create(ActionRequest) (ActionRequest, error)
+	GetByID(string) (ActionRequest, error)
+	Get(status string) ([]ActionRequest, error)
+	// Review moves a pending request to status on behalf of a reviewer other
+	// than its maker. It reports false if the request was no longer pending.
+	Review(id, status, reviewerID, note string) (bool, error)
+	SetOutcome(id, status, errMsg string) error
+	ExpirePending(asOf time.Time) (int64, error)
+}
+
+type ActionRequestUsecase interface {
+	// Perform files an action. It runs immediately unless dual control
+	// applies, in which case it is left pending for a second admin.
+	Perform(request ActionRequest) (ActionRequest, error)
+	Approve(id, checkerID string, checkerRoles []Role) (ActionRequest, error)
+	Reject(id, checkerID string, checkerRoles []Role, note string) (ActionRequest, error)
+	GetActionRequests(status string) ([]ActionRequest, error)
+	GetActionRequest(id string) (ActionRequest, error)
+}
@@ -0,0 +1,86 @@
+package domain
+
+import "time"
+
+const (
+	APIKeyCollection      = "api_keys"
+	APIKeyUsageCollection = "api_key_usage"
+)
+
+// APIScope is an operation an API key may perform on behalf of its owner.
+type APIScope string
+
+const (
+	APIScopeLoansSubmit APIScope = "loans:submit"
+	APIScopeLoansRead   APIScope = "loans:read"
+)
+
+// ValidAPIScopes lists the scopes that can be granted to API keys.
+var ValidAPIScopes = map[APIScope]bool{
+	APIScopeLoansSubmit: true,
+	APIScopeLoansRead:   true,
+}
+
+// API key defaults: requests allowed per minute and how long a key is valid
+// when no limit or expiry is given.
+const (
+	DefaultAPIKeyRateLimit = 60
+	DefaultAPIKeyValidity  = 365 * 24 * time.Hour
+)
+
+// APIKey lets a partner system act as its owning user without logging in.
+// Only the SHA-256 hash of the key is stored; Prefix is kept so admins can
+// tell keys apart.
+type APIKey struct {
+	ID         string     `json:"id" bson:"_id"`
+	Name       string     `json:"name" bson:"name"`
+	Prefix     string     `json:"prefix" bson:"prefix"`
+	KeyHash    string     `json:"-" bson:"key_hash"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	Scopes     []APIScope `json:"scopes" bson:"scopes"`
+	RateLimit  int        `json:"rate_limit" bson:"rate_limit"`
+	CreatedBy  string     `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at"`
+	LastUsedAt time.Time  `json:"last_used_at" bson:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip" bson:"last_used_ip"`
+	RevokedAt  time.Time  `json:"revoked_at" bson:"revoked_at"`
+}
+
+// HasScope reports whether the key was granted scope.
+func (k APIKey) HasScope(scope APIScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyRepository interface {
+	Create(APIKey) (APIKey, error)
+	GetByID(string) (APIKey, error)
+	GetByHash(string) (APIKey, error)
+	// Get lists the keys of a user, or every key when userID is empty.
+	Get(userID string) ([]APIKey, error)
+	Revoke(id string, at time.Time) error
+	// RevokeByUser revokes every active key of the user.
+	RevokeByUser(userID string, at time.Time) error
+	Touch(id, ip string, at time.Time) error
+	// CountRequest counts a request in the key's fixed one-minute window
+	// starting at window and returns the number of requests in it so far.
+	CountRequest(id string, window time.Time) (int, error)
+}
+
+type APIKeyUsecase interface {
+	// CreateAPIKey issues a key and returns it with the plaintext key, which
+	// is never shown again.
+	CreateAPIKey(actorID string, key APIKey) (APIKey, string, error)
+	GetAPIKeys(userID string) ([]APIKey, error)
+	RevokeAPIKey(actorID, id string) error
+	// Authenticate checks a presented key and counts the request against its
+	// rate limit.
+	Authenticate(key, ip string) (APIKey, error)
+	// RecordUse updates the key's last use and writes an audit log entry.
+	RecordUse(key APIKey, method, path, ip string, status int)
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+const BenchmarkRateCollection = "benchmark_rates"
+
+// BenchmarkRate is the value of a benchmark rate from EffectiveDate until the
+// next entry of the same benchmark.
+type BenchmarkRate struct {
+	ID            string    `json:"id" bson:"_id"`
+	Benchmark     string    `json:"benchmark" bson:"benchmark" binding:"required"`
+	Rate          float64   `json:"rate" bson:"rate"`
+	EffectiveDate time.Time `json:"effective_date" bson:"effective_date" binding:"required"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+}
+
+type BenchmarkRateRepository interface {
+	Create(BenchmarkRate) (BenchmarkRate, error)
+	Delete(string) error
+	GetByBenchmark(string) ([]BenchmarkRate, error)
+	GetEffective(benchmark string, date time.Time) (BenchmarkRate, error)
+}
+
+type BenchmarkRateUsecase interface {
+	AddRate(BenchmarkRate) (BenchmarkRate, error)
+	DeleteRate(id string) error
+	GetRates(benchmark string) ([]BenchmarkRate, error)
+	ResetRates(asOf time.Time) (int, error)
+}
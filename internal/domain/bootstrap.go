@@ -0,0 +1,15 @@
+package domain
+
+// ReferenceData is the catalogue seeded into a fresh deployment.
+type ReferenceData struct {
+	Products       []LoanProduct   `json:"products"`
+	BenchmarkRates []BenchmarkRate `json:"benchmark_rates"`
+}
+
+// BootstrapUsecase prepares a fresh deployment from the command line. Public
+// registration never grants admin access; the first super admin is created
+// here instead.
+type BootstrapUsecase interface {
+	CreateSuperAdmin(email, name, password string) (User, error)
+	SeedReferenceData(data ReferenceData) (int, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+const CollateralCollection = "collaterals"
+
+// DefaultMaxLTV is used when no loan-to-value limit is configured.
+const DefaultMaxLTV = 0.8
+
+const (
+	LienStatusNone      = "none"
+	LienStatusPending   = "pending"
+	LienStatusPerfected = "perfected"
+	LienStatusReleased  = "released"
+)
+
+type Collateral struct {
+	ID             string    `json:"id" bson:"_id"`
+	LoanID         string    `json:"loan_id" bson:"loan_id"`
+	Type           string    `json:"type" bson:"type" binding:"required"`
+	Description    string    `json:"description" bson:"description"`
+	AppraisedValue float64   `json:"appraised_value" bson:"appraised_value" binding:"required,gt=0"`
+	ValuationDate  time.Time `json:"valuation_date" bson:"valuation_date"`
+	LienStatus     string    `json:"lien_status" bson:"lien_status"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+}
+
+// LoanToValue is the ratio of a loan amount to the total appraised value of
+// the collateral securing it.
+type LoanToValue struct {
+	LoanID          string       `json:"loan_id"`
+	LoanAmount      float64      `json:"loan_amount"`
+	CollateralValue float64      `json:"collateral_value"`
+	Ratio           float64      `json:"ratio"`
+	Collaterals     []Collateral `json:"collaterals"`
+}
+
+type CollateralRepository interface {
+	Create(Collateral) (Collateral, error)
+	Update(string, Collateral) (Collateral, error)
+	Delete(string) error
+	GetByID(string) (Collateral, error)
+	GetByLoanID(string) ([]Collateral, error)
+}
+
+type CollateralUsecase interface {
+	AddCollateral(loanID string, collateral Collateral) (Collateral, error)
+	UpdateCollateral(id string, collateral Collateral) (Collateral, error)
+	DeleteCollateral(id string) error
+	GetCollateral(id string) (Collateral, error)
+	GetLoanToValue(loanID string) (LoanToValue, error)
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const EmailOutboxCollection = "email_outbox"
+
+// Delivery statuses of outbox emails. Sending emails are claimed by a worker;
+// dead emails used up their attempts and wait for an admin to retry them.
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead"
+)
+
+// Failed deliveries are retried with exponential backoff, starting at
+// DefaultOutboxRetryDelay and capped at DefaultOutboxMaxRetryDelay, until
+// DefaultOutboxMaxAttempts is reached. A worker that claimed an email and
+// did not report back within OutboxClaimTimeout is assumed to have died.
+const (
+	DefaultOutboxMaxAttempts   = 8
+	DefaultOutboxRetryDelay    = 30 * time.Second
+	DefaultOutboxMaxRetryDelay = time.Hour
+	OutboxClaimTimeout         = 5 * time.Minute
+	// OutboxSentRetention is how long delivered emails, whose bodies may
+	// contain links with tokens, are kept.
+	OutboxSentRetention = 24 * time.Hour
+)
+
+// OutboxEmail is a rendered email written in the same transaction as the
+// change that caused it and delivered later by the outbox worker.
+type OutboxEmail struct {
+	ID            string     `json:"id" bson:"_id"`
+	Template      string     `json:"template" bson:"template"`
+	To            []string   `json:"to" bson:"to"`
+	Subject       string     `json:"subject" bson:"subject"`
+	Text          string     `json:"-" bson:"text"`
+	HTML          string     `json:"-" bson:"html"`
+	Status        string     `json:"status" bson:"status"`
+	Attempts      int        `json:"attempts" bson:"attempts"`
+	LastError     string     `json:"last_error,omitempty" bson:"last_error"`
+	CreatedAt     time.Time  `json:"created_at" bson:"created_at"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" bson:"next_attempt_at"`
+	ClaimedUntil  time.Time  `json:"-" bson:"claimed_until"`
+	SentAt        time.Time  `json:"sent_at" bson:"sent_at"`
+	ExpiresAt     *time.Time `json:"-" bson:"expires_at,omitempty"`
+}
+
+// Message returns the email to hand to the mailer.
+func (e OutboxEmail) Message() EmailMessage {
+	return EmailMessage{Template: e.Template, To: e.To, Subject: e.Subject, Text: e.Text, HTML: e.HTML}
+}
+
+type EmailOutboxRepository interface {
+	// WithContext returns a repository whose calls run with ctx, so they can
+	// take part in a transaction.
+	WithContext(ctx context.Context) EmailOutboxRepository
+	Create(OutboxEmail) (OutboxEmail, error)
+	GetByID(id string) (OutboxEmail, error)
+	GetByStatus(status string) ([]OutboxEmail, error)
+	// ClaimDue marks the oldest pending email due at now, or a sending email
+	// whose claim lapsed, as sending until claimedUntil and counts the attempt.
+	ClaimDue(now, claimedUntil time.Time) (OutboxEmail, error)
+	MarkSent(id string, at time.Time) error
+	// Reschedule returns a claimed email to pending after a failed attempt.
+	Reschedule(id string, nextAttemptAt time.Time, lastError string) error
+	MarkDead(id, lastError string) error
+	// Requeue makes a dead email pending again with its attempts reset.
+	Requeue(id string, now time.Time) (OutboxEmail, error)
+	// DeleteByRecipient deletes every email to the address, whatever its
+	// status, and returns how many there were.
+	DeleteByRecipient(address string) (int64, error)
+}
+
+type EmailOutboxUsecase interface {
+	// DeliverDue sends the emails that are due through the mailer and returns
+	// how many were delivered.
+	DeliverDue(mailer Mailer, now time.Time) (int, error)
+	GetDeadLetters() ([]OutboxEmail, error)
+	RetryDeadLetter(actorID, id string) (OutboxEmail, error)
+}
@@ -0,0 +1,23 @@
+package domain
+
+// EmailTemplate is an email template and the locales it is available in.
+type EmailTemplate struct {
+	Name    string   `json:"name"`
+	Locales []string `json:"locales"`
+}
+
+// EmailPreview is a template rendered with example data.
+type EmailPreview struct {
+	Name    string `json:"name"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+type EmailTemplateUsecase interface {
+	GetEmailTemplates() ([]EmailTemplate, error)
+	// PreviewEmailTemplate renders a template in the locale that would be
+	// chosen for a user with the given language.
+	PreviewEmailTemplate(name, locale string) (EmailPreview, error)
+}
@@ -1,29 +1,126 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 const LoanColletion = "loans"
 
+// Loan statuses. An approved application becomes an offer that the borrower
+// accepts or declines; accepted loans are active and accrue interest.
+const (
+	LoanStatusPending  = "pending"
+	LoanStatusRejected = "rejected"
+	LoanStatusOffered  = "offered"
+	LoanStatusAccepted = "accepted"
+	LoanStatusDeclined = "declined"
+	LoanStatusExpired  = "expired"
+	// LoanStatusWrittenOff marks an accepted loan deemed uncollectable.
+	LoanStatusWrittenOff = "written_off"
+)
+
+// DefaultOfferValidity is how long an offer stays open when not configured.
+const DefaultOfferValidity = 7 * 24 * time.Hour
+
+// DefaultTermMonths is used when neither the application, its product nor the
+// configuration specify a term.
+const DefaultTermMonths = 12
+
+// DefaultMaxTermMonths is the longest term a loan or product may have when
+// not configured.
+const DefaultMaxTermMonths = 480
+
 type Loan struct {
-	ID        string    `json:"id" bson:"_id"`
-	UserID    string    `json:"user_id" bson:"user_id"`
-	Ammount   string    `json:"ammount" binding:"required"`
-	CreatedAt time.Time `json:"created_at" bson:"created_at"`
-	Status    string    `json:"status"`
+	ID                 string    `json:"id" bson:"_id"`
+	UserID             string    `json:"user_id" bson:"user_id"`
+	Ammount            string    `json:"ammount" binding:"required"`
+	CreatedAt          time.Time `json:"created_at" bson:"created_at"`
+	Status             string    `json:"status"`
+	ProductID          string    `json:"product_id" bson:"product_id"`
+	TermMonths         int       `json:"term_months" bson:"term_months"`
+	InterestRate       float64   `json:"interest_rate" bson:"interest_rate"`
+	Fees               float64   `json:"fees" bson:"fees"`
+	DayCountConvention string    `json:"day_count_convention" bson:"day_count_convention"`
+	ApprovedAt         time.Time `json:"approved_at" bson:"approved_at"`
+	AcceptedAt         time.Time `json:"accepted_at" bson:"accepted_at"`
+	WrittenOffAt       time.Time `json:"written_off_at" bson:"written_off_at"`
+	Offer              *Offer    `json:"offer,omitempty" bson:"offer,omitempty"`
+	// AccruedInterest is the interest accrued but not yet paid, and
+	// AccruedThrough the day from which the next accrual starts.
+	AccruedInterest float64   `json:"accrued_interest" bson:"accrued_interest"`
+	AccruedThrough  time.Time `json:"accrued_through" bson:"accrued_through"`
+	// NextRateReset is only set on variable-rate loans.
+	NextRateReset time.Time     `json:"next_rate_reset" bson:"next_rate_reset"`
+	Schedule      []Installment `json:"schedule" bson:"schedule"`
+	// CostOfCredit is derived from the schedule when a loan is read.
+	CostOfCredit *CostOfCredit `json:"cost_of_credit,omitempty" bson:"-"`
+}
+
+// Offer holds the final terms of an approved loan that the borrower must
+// accept before it expires.
+type Offer struct {
+	Amount         float64      `json:"amount" bson:"amount"`
+	InterestRate   float64      `json:"interest_rate" bson:"interest_rate"`
+	TermMonths     int          `json:"term_months" bson:"term_months"`
+	MonthlyPayment float64      `json:"monthly_payment" bson:"monthly_payment"`
+	CostOfCredit   CostOfCredit `json:"cost_of_credit" bson:"cost_of_credit"`
+	ExpiresAt      time.Time    `json:"expires_at" bson:"expires_at"`
+	CreatedAt      time.Time    `json:"created_at" bson:"created_at"`
+	RespondedAt    time.Time    `json:"responded_at" bson:"responded_at"`
+}
+
+// CostOfCredit is the consumer-credit disclosure of a loan. Fees are paid
+// upfront and deducted from the amount financed; the APR includes them.
+type CostOfCredit struct {
+	AmountFinanced     float64 `json:"amount_financed" bson:"amount_financed"`
+	Fees               float64 `json:"fees" bson:"fees"`
+	TotalInterest      float64 `json:"total_interest" bson:"total_interest"`
+	TotalCostOfCredit  float64 `json:"total_cost_of_credit" bson:"total_cost_of_credit"`
+	TotalAmountPayable float64 `json:"total_amount_payable" bson:"total_amount_payable"`
+	APR                float64 `json:"apr" bson:"apr"`
+}
+
+// Installment is a single payment of a loan's amortization schedule.
+type Installment struct {
+	Number    int       `json:"number" bson:"number"`
+	DueDate   time.Time `json:"due_date" bson:"due_date"`
+	Payment   float64   `json:"payment" bson:"payment"`
+	Principal float64   `json:"principal" bson:"principal"`
+	Interest  float64   `json:"interest" bson:"interest"`
+	Balance   float64   `json:"balance" bson:"balance"`
+	// PaidAt is set once the installment's payment has been recorded.
+	PaidAt time.Time `json:"paid_at" bson:"paid_at"`
 }
 
 type LoanRepository interface {
+	// WithContext returns a repository whose calls run with ctx, so they can
+	// take part in a transaction.
+	WithContext(ctx context.Context) LoanRepository
 	GetByID(id string) (Loan, error)
 	Get(filter map[string]string) ([]Loan, error)
 	Delete(loanID string) error
 	Update(id string, updateData Loan) (Loan, error)
 	Create(loan Loan) (Loan, error)
+	CountByProduct(productID string) (int64, error)
+	// TransitionStatus sets the loan's status to to, provided it is still from.
+	TransitionStatus(id, from, to string) error
+	// MarkInstallmentPaid records the payment of an unpaid installment.
+	MarkInstallmentPaid(id string, number int, at time.Time) error
+	// SetAccruedInterest stores the interest accrued but not yet paid,
+	// including zero, and the day accrual has reached.
+	SetAccruedInterest(id string, amount float64, through time.Time) error
 }
 
 type LoanUsecase interface {
-	CreateLoan(userID string, amount string) (Loan, error)
+	CreateLoan(userID string, application Loan) (Loan, error)
 	ViewLoanStatus(id string) (Loan, error)
 	ViewAllLoans(filter map[string]string) ([]Loan, error)
 	ApproveRejectLoan(id string, status string) (Loan, error)
 	DeleteLoan(id string) error
+	WriteOffLoan(id, reason string) (Loan, error)
+	AcceptOffer(id string, userID string) (Loan, error)
+	DeclineOffer(id string, userID string) (Loan, error)
+	ExpireOffers(asOf time.Time) (int, error)
+	RecordPayment(id string, number int) (Loan, error)
 }
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+const LoginThrottleCollection = "login_throttles"
+
+// Login throttling defaults used when the lockout section is not configured.
+const (
+	DefaultMaxAccountAttempts = 5
+	DefaultMaxIPAttempts      = 20
+	DefaultAttemptWindow      = 15 * time.Minute
+	DefaultLockoutDuration    = 15 * time.Minute
+	DefaultLoginDelay         = time.Second
+	MaxLoginDelay             = 30 * time.Second
+)
+
+// LoginThrottle counts the recent failed logins of an account or a client
+// IP, keyed as "account:<email>" or "ip:<address>". Failures older than the
+// attempt window no longer count.
+type LoginThrottle struct {
+	Key           string    `bson:"_id"`
+	Failures      int       `bson:"failures"`
+	LastFailureAt time.Time `bson:"last_failure_at"`
+	LockedUntil   time.Time `bson:"locked_until"`
+	ExpiresAt     time.Time `bson:"expires_at"`
+}
+
+type LoginThrottleRepository interface {
+	// Get returns the throttle for key, or a zero throttle if there is none.
+	Get(key string) (LoginThrottle, error)
+	RecordFailure(key string, at time.Time, window time.Duration) (LoginThrottle, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
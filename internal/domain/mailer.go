@@ -0,0 +1,17 @@
+package domain
+
+// EmailMessage is a rendered email ready to be delivered. Template names the
+// template it was rendered from.
+type EmailMessage struct {
+	Template string   `json:"template"`
+	To       []string `json:"to"`
+	Subject  string   `json:"subject"`
+	Text     string   `json:"text"`
+	HTML     string   `json:"html"`
+}
+
+// Mailer delivers emails. The transport is chosen by configuration and
+// injected into the outbox worker; usecases queue emails in the outbox.
+type Mailer interface {
+	Send(EmailMessage) error
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const NotificationCollection = "notifications"
+
+// NotificationType is a kind of borrower notification. Each type is also the
+// name of the email template it is rendered from.
+type NotificationType string
+
+const (
+	NotificationLoanSubmitted  NotificationType = "loan_submitted"
+	NotificationLoanApproved   NotificationType = "loan_approved"
+	NotificationLoanRejected   NotificationType = "loan_rejected"
+	NotificationLoanDisbursed  NotificationType = "loan_disbursed"
+	NotificationPaymentDue     NotificationType = "payment_due"
+	NotificationPaymentOverdue NotificationType = "payment_overdue"
+)
+
+// NotificationTypes lists every notification type, in lifecycle order.
+var NotificationTypes = []NotificationType{
+	NotificationLoanSubmitted,
+	NotificationLoanApproved,
+	NotificationLoanRejected,
+	NotificationLoanDisbursed,
+	NotificationPaymentDue,
+	NotificationPaymentOverdue,
+}
+
+// MandatoryNotifications are sent regardless of the user's preferences, as
+// they inform the borrower of decisions on and obligations under their loan.
+var MandatoryNotifications = map[NotificationType]bool{
+	NotificationLoanApproved:   true,
+	NotificationLoanRejected:   true,
+	NotificationLoanDisbursed:  true,
+	NotificationPaymentOverdue: true,
+}
+
+// DefaultPaymentReminderDays is how many days before an installment is due
+// the borrower is reminded of it.
+const DefaultPaymentReminderDays = 3
+
+// Notification is an in-app message to a borrower, also sent by email. Key
+// identifies the event it reports, so each event is notified only once.
+type Notification struct {
+	ID        string           `json:"id" bson:"_id"`
+	UserID    string           `json:"user_id" bson:"user_id"`
+	Type      NotificationType `json:"type" bson:"type"`
+	LoanID    string           `json:"loan_id" bson:"loan_id"`
+	Title     string           `json:"title" bson:"title"`
+	Message   string           `json:"message" bson:"message"`
+	Key       string           `json:"-" bson:"key"`
+	CreatedAt time.Time        `json:"created_at" bson:"created_at"`
+	ReadAt    time.Time        `json:"read_at" bson:"read_at"`
+}
+
+// NotificationPreference tells whether the user receives a type of
+// notification. Mandatory types are always enabled.
+type NotificationPreference struct {
+	Type      NotificationType `json:"type"`
+	Enabled   bool             `json:"enabled"`
+	Mandatory bool             `json:"mandatory"`
+}
+
+type NotificationRepository interface {
+	// WithContext returns a repository whose calls run with ctx, so they can
+	// take part in a transaction.
+	WithContext(ctx context.Context) NotificationRepository
+	// Create stores the notification unless one with the same key exists.
+	Create(Notification) (Notification, error)
+	GetByUser(userID string) ([]Notification, error)
+	MarkRead(userID, id string, at time.Time) error
+}
+
+type NotificationUsecase interface {
+	GetNotifications(userID string) ([]Notification, error)
+	MarkRead(userID, id string) error
+	GetPreferences(userID string) ([]NotificationPreference, error)
+	// UpdatePreferences enables or disables the given types. Mandatory types
+	// cannot be disabled.
+	UpdatePreferences(userID string, enabled map[NotificationType]bool) ([]NotificationPreference, error)
+	// SendPaymentNotices reminds borrowers of installments coming due and
+	// notifies them of overdue ones, returning how many were sent.
+	SendPaymentNotices(asOf time.Time) (int, error)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+const OIDCLoginCollection = "oidc_logins"
+
+// OIDCLoginValidity is how long a user has to complete a login at the
+// identity provider.
+const OIDCLoginValidity = 10 * time.Minute
+
+// OIDCLogin is a login started at the identity provider, keyed by the state
+// parameter. It holds the PKCE verifier and nonce needed to complete it and
+// can only be completed once.
+type OIDCLogin struct {
+	State        string    `bson:"_id"`
+	Nonce        string    `bson:"nonce"`
+	CodeVerifier string    `bson:"code_verifier"`
+	CreatedAt    time.Time `bson:"created_at"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+}
+
+type OIDCLoginRepository interface {
+	Create(OIDCLogin) error
+	// Consume removes an unexpired login and returns it.
+	Consume(state string) (OIDCLogin, error)
+}
+
+// OIDCUsecase logs staff in through an external identity provider.
+type OIDCUsecase interface {
+	// StartLogin returns the URL to send the browser to.
+	StartLogin() (string, error)
+	// CompleteLogin handles the provider's callback and reports whether the
+	// provider vouched for a second factor.
+	CompleteLogin(state, code string) (User, bool, error)
+}
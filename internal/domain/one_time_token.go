@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const OneTimeTokenCollection = "one_time_tokens"
+
+// Purposes of the single-use tokens. All but the MFA challenge, which is
+// returned by the login, are sent by email.
+const (
+	TokenEmailVerification = "emailVerification"
+	TokenPasswordReset     = "passwordReset"
+	TokenAccountUnlock     = "accountUnlock"
+	TokenEmailChange       = "emailChange"
+	TokenMFAChallenge      = "mfaChallenge"
+)
+
+// Verification emails can be resent at most once per cooldown and a limited
+// number of times per hour.
+const (
+	VerificationResendCooldown    = time.Minute
+	MaxVerificationResendsPerHour = 5
+)
+
+// OneTimeToken is an emailed token stored by hash. It can be used once and
+// is invalidated when a newer token for the same purpose is issued. Email is
+// the address the token was sent to.
+type OneTimeToken struct {
+	ID        string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	Purpose   string    `bson:"purpose"`
+	Email     string    `bson:"email"`
+	TokenHash string    `bson:"token_hash"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	UsedAt    time.Time `bson:"used_at"`
+}
+
+type OneTimeTokenRepository interface {
+	// WithContext returns a repository whose calls run with ctx, so they can
+	// take part in a transaction.
+	WithContext(ctx context.Context) OneTimeTokenRepository
+	Create(OneTimeToken) (OneTimeToken, error)
+	// Find returns an unused, unexpired token without using it up.
+	Find(hash, purpose string) (OneTimeToken, error)
+	// Consume marks an unused, unexpired token as used and returns it.
+	Consume(hash, purpose string) (OneTimeToken, error)
+	// Invalidate marks every unused token of the user for purpose as used.
+	Invalidate(userID, purpose string) error
+	CountIssuedSince(userID, purpose string, since time.Time) (int64, error)
+	GetLatest(userID, purpose string) (OneTimeToken, error)
+}
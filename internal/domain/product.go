@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+const LoanProductCollection = "loan_products"
+
+const (
+	RateTypeFixed    = "fixed"
+	RateTypeVariable = "variable"
+)
+
+// LoanProduct describes the pricing of a family of loans. Fixed-rate products
+// use FixedRate, variable-rate products float Margin over the Benchmark and
+// reset every ResetFrequencyMonths. Origination fees are a flat amount plus
+// OriginationFeeRate of the principal.
+type LoanProduct struct {
+	ID                   string    `json:"id" bson:"_id"`
+	Name                 string    `json:"name" bson:"name" binding:"required"`
+	RateType             string    `json:"rate_type" bson:"rate_type" binding:"required,oneof=fixed variable"`
+	FixedRate            float64   `json:"fixed_rate" bson:"fixed_rate"`
+	Benchmark            string    `json:"benchmark" bson:"benchmark"`
+	Margin               float64   `json:"margin" bson:"margin"`
+	ResetFrequencyMonths int       `json:"reset_frequency_months" bson:"reset_frequency_months"`
+	TermMonths           int       `json:"term_months" bson:"term_months"`
+	OriginationFee       float64   `json:"origination_fee" bson:"origination_fee"`
+	OriginationFeeRate   float64   `json:"origination_fee_rate" bson:"origination_fee_rate"`
+	DayCountConvention   string    `json:"day_count_convention" bson:"day_count_convention"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at"`
+}
+
+type LoanProductRepository interface {
+	Create(LoanProduct) (LoanProduct, error)
+	Update(string, LoanProduct) (LoanProduct, error)
+	Delete(string) error
+	Get() ([]LoanProduct, error)
+	GetByID(string) (LoanProduct, error)
+}
+
+type LoanProductUsecase interface {
+	CreateProduct(LoanProduct) (LoanProduct, error)
+	UpdateProduct(id string, product LoanProduct) (LoanProduct, error)
+	DeleteProduct(id string) error
+	GetProducts() ([]LoanProduct, error)
+	GetProduct(id string) (LoanProduct, error)
+}
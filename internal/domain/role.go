@@ -0,0 +1,71 @@
+package domain
+
+// Role is a named set of permissions granted to staff users.
+type Role string
+
+const (
+	RoleLoanOfficer Role = "loan_officer"
+	RoleUnderwriter Role = "underwriter"
+	RoleAuditor     Role = "auditor"
+	RoleSupport     Role = "support"
+	RoleSuperAdmin  Role = "super_admin"
+)
+
+// Permission is a single action checked by the RequirePermission middleware.
+type Permission string
+
+const (
+	PermissionLoansRead        Permission = "loans:read"
+	PermissionLoansApprove     Permission = "loans:approve"
+	PermissionLoansDelete      Permission = "loans:delete"
+	PermissionLoansWriteOff    Permission = "loans:write_off"
+	PermissionCollateralManage Permission = "collateral:manage"
+	PermissionProductsManage   Permission = "products:manage"
+	PermissionLogsRead         Permission = "logs:read"
+	PermissionUsersRead        Permission = "users:read"
+	PermissionUsersManage      Permission = "users:manage"
+	PermissionRolesAssign      Permission = "roles:assign"
+	PermissionAPIKeysManage    Permission = "api_keys:manage"
+	PermissionPaymentsRecord   Permission = "payments:record"
+)
+
+// RolePermissions maps each role to the permissions it grants.
+var RolePermissions = map[Role][]Permission{
+	RoleLoanOfficer: {PermissionLoansRead, PermissionCollateralManage, PermissionPaymentsRecord, PermissionUsersRead},
+	RoleUnderwriter: {PermissionLoansRead, PermissionLoansApprove, PermissionLoansWriteOff, PermissionUsersRead},
+	RoleAuditor:     {PermissionLoansRead, PermissionLogsRead, PermissionUsersRead},
+	RoleSupport:     {PermissionLoansRead, PermissionUsersRead},
+	RoleSuperAdmin: {
+		PermissionLoansRead, PermissionLoansApprove, PermissionLoansDelete, PermissionLoansWriteOff,
+		PermissionCollateralManage, PermissionProductsManage, PermissionLogsRead,
+		PermissionUsersRead, PermissionUsersManage, PermissionRolesAssign, PermissionAPIKeysManage,
+		PermissionPaymentsRecord,
+	},
+}
+
+// ValidRole reports whether r is a known role.
+func ValidRole(r Role) bool {
+	_, ok := RolePermissions[r]
+	return ok
+}
+
+// HasPermission reports whether any of the roles grants p.
+func HasPermission(roles []Role, p Permission) bool {
+	for _, r := range roles {
+		for _, granted := range RolePermissions[r] {
+			if granted == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EffectiveRoles returns the user's roles. Admins created before roles
+// existed have none and are treated as super admins.
+func EffectiveRoles(user User) []Role {
+	if len(user.Roles) == 0 && user.IsAdmin {
+		return []Role{RoleSuperAdmin}
+	}
+	return user.Roles
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+const (
+	SessionCollection      = "sessions"
+	RefreshTokenCollection = "refresh_tokens"
+)
+
+// Session is a login on a single device. Its refresh tokens form one family:
+// each refresh consumes the current token and issues the next one.
+type Session struct {
+	ID         string    `json:"id" bson:"_id"`
+	UserID     string    `json:"user_id" bson:"user_id"`
+	Device     string    `json:"device" bson:"device"`
+	IP         string    `json:"ip" bson:"ip"`
+	MFA        bool      `json:"mfa" bson:"mfa"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at" bson:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`
+	RevokedAt  time.Time `json:"-" bson:"revoked_at"`
+	Current    bool      `json:"current" bson:"-"`
+}
+
+// RefreshToken is stored by the SHA-256 hash of the opaque token handed to the
+// client, never by the token itself.
+type RefreshToken struct {
+	ID        string    `bson:"_id"`
+	SessionID string    `bson:"session_id"`
+	UserID    string    `bson:"user_id"`
+	TokenHash string    `bson:"token_hash"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	UsedAt    time.Time `bson:"used_at"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type SessionRepository interface {
+	Create(Session) (Session, error)
+	GetByID(string) (Session, error)
+	GetActiveByUserID(string) ([]Session, error)
+	Touch(id, ip string) error
+	Revoke(string) error
+	// RevokeByUserID revokes every active session of a user except exceptID.
+	RevokeByUserID(userID, exceptID string) error
+}
+
+type RefreshTokenRepository interface {
+	Create(RefreshToken) (RefreshToken, error)
+	GetByHash(string) (RefreshToken, error)
+	MarkUsed(string) (bool, error)
+	DeleteBySessionID(string) error
+}
+
+type SessionUsecase interface {
+	CreateSession(user User, device, ip string, mfa bool) (TokenPair, error)
+	Refresh(refreshToken, ip string) (TokenPair, error)
+	ValidateSession(sessionID, userID string) error
+	GetSessions(userID, currentSessionID string) ([]Session, error)
+	RevokeSession(userID, sessionID string) error
+	RevokeAllSessions(userID string) error
+	RevokeOtherSessions(userID, currentSessionID string) error
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+const SigningKeyCollection = "signing_keys"
+
+// SigningKey is a key pair used to sign access tokens. The newest key that is
+// not retired signs new tokens; retired keys keep verifying tokens until
+// ExpiresAt so that rotation does not log anyone out.
+type SigningKey struct {
+	ID         string    `json:"kid" bson:"_id"`
+	Algorithm  string    `json:"alg" bson:"algorithm"`
+	PrivateKey string    `json:"-" bson:"private_key"`
+	PublicKey  string    `json:"public_key" bson:"public_key"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	RetiredAt  time.Time `json:"retired_at" bson:"retired_at"`
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// AccessClaims identifies the user and session an access token was issued to.
+type AccessClaims struct {
+	UserID    string
+	Email     string
+	IsAdmin   bool
+	SessionID string
+	MFA       bool
+	Roles     []Role
+}
+
+type SigningKeyRepository interface {
+	Create(SigningKey) (SigningKey, error)
+	GetValid() ([]SigningKey, error)
+	Retire(id string, retiredAt, expiresAt time.Time) error
+}
+
+type SigningKeyUsecase interface {
+	RotateIfDue(now time.Time) (bool, error)
+	Rotate() (SigningKey, error)
+	GetValidKeys() ([]SigningKey, error)
+	SignAccessToken(user User, session Session, ttl time.Duration) (string, error)
+	VerifyAccessToken(token string) (AccessClaims, error)
+}
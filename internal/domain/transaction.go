@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Transactor runs a function in a database transaction. Repositories take
+// part in it when bound to the context passed to fn with WithContext.
+type Transactor interface {
+	WithTransaction(fn func(ctx context.Context) error) error
+}
@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"context"
+	"time"
+)
+
 const UserCollection = "users"
 
 type User struct {
@@ -7,27 +12,164 @@ type User struct {
 	Email    string `json:"email"`
 	Name     string `json:"name"`
 	Password string `json:"password"`
+	Phone    string `json:"phone" bson:"phone"`
+	Address  string `json:"address" bson:"address"`
+	// Language is the user's preferred locale for emails, such as "en" or
+	// "fr-CA".
+	Language string `json:"language" bson:"language"`
 	IsActive bool   `json:"is_active" bson:"is_active"`
 	IsAdmin  bool   `json:"is_admin" bson:"is_admin"`
+	Roles    []Role `json:"roles" bson:"roles"`
+	MFA      MFA    `json:"mfa" bson:"mfa"`
+	// PasswordResetRequired blocks logins until the user resets their
+	// password, e.g. after an admin forced a reset.
+	PasswordResetRequired bool `json:"password_reset_required" bson:"password_reset_required"`
+	// PasswordHistory holds the hashes of previous passwords, newest first.
+	PasswordHistory []string `json:"-" bson:"password_history"`
+	// SuspendedAt is set while an admin has suspended the account. It is
+	// kept apart from IsActive, which only tells whether the email was
+	// verified, so verifying an email cannot lift a suspension.
+	SuspendedAt time.Time `json:"suspended_at" bson:"suspended_at"`
+	// DeletedAt is set when the user deleted their account and their
+	// personal data was anonymized.
+	DeletedAt time.Time `json:"deleted_at" bson:"deleted_at"`
+	// ExternalIdentity links the user to an identity provider account after
+	// their first single sign-on login.
+	ExternalIdentity ExternalIdentity `json:"-" bson:"external_identity"`
+	// NotificationOptOuts are the notification types the user chose not to
+	// receive.
+	NotificationOptOuts []NotificationType `json:"-" bson:"notification_opt_outs"`
+}
+
+// ExternalIdentity identifies a user at an OpenID Connect provider.
+type ExternalIdentity struct {
+	Issuer  string `bson:"issuer"`
+	Subject string `bson:"subject"`
+}
+
+// UserResponse is the public view of a user. Unlike User it never carries
+// the password hash, MFA secrets or other credentials.
+type UserResponse struct {
+	ID                    string    `json:"id"`
+	Email                 string    `json:"email"`
+	Name                  string    `json:"name"`
+	Phone                 string    `json:"phone"`
+	Address               string    `json:"address"`
+	Language              string    `json:"language"`
+	IsActive              bool      `json:"is_active"`
+	IsAdmin               bool      `json:"is_admin"`
+	Roles                 []Role    `json:"roles"`
+	MFAEnabled            bool      `json:"mfa_enabled"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	SuspendedAt           time.Time `json:"suspended_at,omitempty"`
+	DeletedAt             time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewUserResponse(user User) UserResponse {
+	roles := user.Roles
+	if roles == nil {
+		roles = []Role{}
+	}
+	return UserResponse{
+		ID:                    user.ID,
+		Email:                 user.Email,
+		Name:                  user.Name,
+		Phone:                 user.Phone,
+		Address:               user.Address,
+		Language:              user.Language,
+		IsActive:              user.IsActive,
+		IsAdmin:               user.IsAdmin,
+		Roles:                 roles,
+		MFAEnabled:            user.MFA.Enabled,
+		PasswordResetRequired: user.PasswordResetRequired,
+		SuspendedAt:           user.SuspendedAt,
+		DeletedAt:             user.DeletedAt,
+	}
+}
+
+// Password policy defaults used when not configured.
+const (
+	DefaultPasswordMinLength   = 12
+	DefaultPasswordHistorySize = 5
+)
+
+// UserUpdate is a partial update of a user. Nil fields are left unchanged,
+// so false and empty values can be set explicitly.
+type UserUpdate struct {
+	Email                 *string
+	Name                  *string
+	Phone                 *string
+	Address               *string
+	Language              *string
+	Password              *string
+	IsActive              *bool
+	IsAdmin               *bool
+	PasswordResetRequired *bool
+	PasswordHistory       *[]string
+}
+
+// MFA holds a user's TOTP second factor. Secrets are stored encrypted and
+// recovery codes hashed; none of them are ever serialized to clients.
+type MFA struct {
+	Enabled       bool     `json:"enabled" bson:"enabled"`
+	Secret        string   `json:"-" bson:"secret"`
+	PendingSecret string   `json:"-" bson:"pending_secret"`
+	RecoveryCodes []string `json:"-" bson:"recovery_codes"`
+	LastUsedStep  int64    `json:"-" bson:"last_used_step"`
+}
+
+// MFAEnrollment is returned when a user starts enrolling an authenticator.
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
 }
 
 type UserRepository interface {
+	// WithContext returns a repository whose calls run with ctx, so they can
+	// take part in a transaction.
+	WithContext(ctx context.Context) UserRepository
 	Create(User) (User, error)
-	Update(string, User) (User, error)
+	Update(string, UserUpdate) (User, error)
 	Delete(string) error
 	Get() ([]User, error)
 	GetByID(string) (User, error)
 	GetByEmail(string) (User, error)
+	UpdateMFA(id string, mfa MFA) error
+	UseTOTPStep(id string, step int64) (bool, error)
+	ConsumeRecoveryCode(id, hash string) (bool, error)
+	SetRoles(id string, roles []Role) error
+	// SetSuspendedAt suspends the user at the given time, or lifts the
+	// suspension when it is zero.
+	SetSuspendedAt(id string, at time.Time) error
+	CountByRole(Role) (int64, error)
+	Anonymize(id string, at time.Time) error
+	GetByExternalIdentity(identity ExternalIdentity) (User, error)
+	LinkExternalIdentity(id string, identity ExternalIdentity) error
+	SetNotificationOptOuts(id string, optOuts []NotificationType) error
 }
 
 type UserUsecases interface {
 	Register(User) (User, error)
 	VerifyEmail(token, email string) error
-	Login(password, email string) (User, error)
+	ResendVerification(email string) error
+	Login(email, password, ip string) (User, string, error)
+	VerifyMFA(challengeToken, code, ip string) (User, error)
+	UnlockAccount(token, email string) error
+	EnrollMFA(userID string) (MFAEnrollment, error)
+	ConfirmMFA(userID, code string) ([]string, error)
+	DisableMFA(userID, code string) error
 	GetProfile(userID string) (User, error)
 	ForgetPassword(email string) error
 	ResetPassword(token, email, newPassword string) error
-	RefreshAccessToken(refreshToken string) (string, error)
 	GetAllUsers() ([]User, error)
 	GetUserByID(string) (User, error)
+	AssignRoles(actorID, userID string, roles []Role) (User, error)
+	SetUserActive(actorID, userID string, active bool) (User, error)
+	ForcePasswordReset(actorID, userID string) error
+	DeleteUser(actorID, userID string) error
+	ChangePassword(userID, sessionID, currentPassword, newPassword string) error
+	RequestEmailChange(userID, newEmail, password string) error
+	ConfirmEmailChange(token string) (User, error)
+	UpdateProfile(userID string, update UserUpdate) (User, error)
+	DeleteAccount(userID, password string) error
 }
@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/internal/usecases"
+	"log"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+)
+
+// Start launches the background jobs. Each job runs once immediately and then
+// on its own interval for the lifetime of the process.
+func Start(config config.Config, db mongoifc.Database, mailer domain.Mailer) {
+	signingKeyUsecase := usecases.NewSigningKeyUsecase(db)
+	every(time.Hour, func() {
+		rotated, err := signingKeyUsecase.RotateIfDue(time.Now())
+		if err != nil {
+			log.Printf("signing key rotation failed: %v", err)
+			return
+		}
+		if rotated {
+			log.Printf("signing key rotated")
+		}
+	})
+	accrualUsecase := usecases.NewInterestAccrualUsecase(db)
+	benchmarkUsecase := usecases.NewBenchmarkRateUsecase(db)
+	loanUsecase := usecases.NewLoanUsecase(db)
+	every(interval(config.Loan.OfferExpiryInterval, time.Hour), func() {
+		expired, err := loanUsecase.ExpireOffers(time.Now())
+		if err != nil {
+			log.Printf("offer expiry failed: %v", err)
+			return
+		}
+		log.Printf("offer expiry expired %d offers", expired)
+	})
+	every(interval(config.Loan.RateResetInterval, 24*time.Hour), func() {
+		reset, err := benchmarkUsecase.ResetRates(time.Now())
+		if err != nil {
+			log.Printf("rate reset failed: %v", err)
+			return
+		}
+		log.Printf("rate reset repriced %d loans", reset)
+	})
+	outboxUsecase := usecases.NewEmailOutboxUsecase(db)
+	every(interval(config.Outbox.Interval, 10*time.Second), func() {
+		sent, err := outboxUsecase.DeliverDue(mailer, time.Now())
+		if err != nil {
+			log.Printf("email delivery failed: %v", err)
+			return
+		}
+		if sent > 0 {
+			log.Printf("email delivery sent %d emails", sent)
+		}
+	})
+	notificationUsecase := usecases.NewNotificationUsecase(db)
+	every(interval(config.Notifications.Interval, time.Hour), func() {
+		sent, err := notificationUsecase.SendPaymentNotices(time.Now())
+		if err != nil {
+			log.Printf("payment notices failed: %v", err)
+			return
+		}
+		log.Printf("payment notices sent %d notifications", sent)
+	})
+	every(interval(config.Loan.AccrualInterval, 24*time.Hour), func() {
+		created, err := accrualUsecase.AccrueInterest(time.Now())
+		if err != nil {
+			log.Printf("interest accrual failed: %v", err)
+			return
+		}
+		log.Printf("interest accrual created %d entries", created)
+	})
+}
+
+func every(d time.Duration, job func()) {
+	go func() {
+		job()
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for range ticker.C {
+			job()
+		}
+	}()
+}
+
+func interval(value string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
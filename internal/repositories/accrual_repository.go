@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrAccrualExists = errors.New("interest already accrued for this date")
+
+type interestAccrualRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewInterestAccrualRepository(db mongoifc.Database) domain.InterestAccrualRepository {
+	c := db.Collection(domain.InterestAccrualCollection)
+	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "loan_id", Value: 1}, {Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return &interestAccrualRepository{collection: c}
+}
+
+func (r *interestAccrualRepository) Create(accrual domain.InterestAccrual) (domain.InterestAccrual, error) {
+	accrual.ID = primitive.NewObjectID().Hex()
+	_, err := r.collection.InsertOne(context.TODO(), accrual)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.InterestAccrual{}, ErrAccrualExists
+		}
+		return domain.InterestAccrual{}, err
+	}
+	return accrual, nil
+}
+
+func (r *interestAccrualRepository) GetByLoanID(loanID string) ([]domain.InterestAccrual, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "date", Value: 1}})
+	cursor, err := r.collection.Find(context.TODO(), bson.M{"loan_id": loanID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	accruals := []domain.InterestAccrual{}
+	if err := cursor.All(context.TODO(), &accruals); err != nil {
+		return nil, err
+	}
+	return accruals, nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrActionRequestNotFound = errors.New("action request not found")
+
+type actionRequestRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewActionRequestRepository(db mongoifc.Database) domain.ActionRequestRepository {
+	c := db.Collection(domain.ActionRequestCollection)
+	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "requested_at", Value: -1}},
+	})
+	return &actionRequestRepository{collection: c}
+}
+
+func (r *actionRequestRepository) Create(request domain.ActionRequest) (domain.ActionRequest, error) {
+	request.ID = primitive.NewObjectID().Hex()
+	if _, err := r.collection.InsertOne(context.TODO(), request); err != nil {
+		return domain.ActionRequest{}, err
+	}
+	return request, nil
+}
+
+func (r *actionRequestRepository) GetByID(id string) (domain.ActionRequest, error) {
+	var request domain.ActionRequest
+	err := r.collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&request)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.ActionRequest{}, ErrActionRequestNotFound
+		}
+		return domain.ActionRequest{}, err
+	}
+	return request, nil
+}
+
+func (r *actionRequestRepository) Get(status string) ([]domain.ActionRequest, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.M{"requested_at": -1})
+	cursor, err := r.collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	requests := []domain.ActionRequest{}
+	if err := cursor.All(context.TODO(), &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *actionRequestRepository) Review(id, status, reviewerID, note string) (bool, error) {
+	filter := bson.M{
+		"_id":          id,
+		"status":       domain.ActionStatusPending,
+		"requested_by": bson.M{"$ne": reviewerID},
+	}
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"reviewed_by": reviewerID,
+		"reviewed_at": time.Now(),
+		"review_note": note,
+	}}
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *actionRequestRepository) SetOutcome(id, status, errMsg string) error {
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"executed_at": time.Now(),
+		"error":       errMsg,
+	}}
+	_, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+	return err
+}
+
+// ExpirePending marks pending requests that expired before asOf.
+func (r *actionRequestRepository) ExpirePending(asOf time.Time) (int64, error) {
+	filter := bson.M{"status": domain.ActionStatusPending, "expires_at": bson.M{"$lte": asOf}}
+	update := bson.M{"$set": bson.M{"status": domain.ActionStatusExpired}}
+	result, err := r.collection.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type apiKeyRepository struct {
+	collection      mongoifc.Collection
+	usageCollection mongoifc.Collection
+}
+
+func NewAPIKeyRepository(db mongoifc.Database) domain.APIKeyRepository {
+	c := db.Collection(domain.APIKeyCollection)
+	c.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"key_hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"user_id": 1}},
+	})
+	usage := db.Collection(domain.APIKeyUsageCollection)
+	usage.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"expires_at": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &apiKeyRepository{collection: c, usageCollection: usage}
+}
+
+func (r *apiKeyRepository) Create(key domain.APIKey) (domain.APIKey, error) {
+	key.ID = primitive.NewObjectID().Hex()
+	if _, err := r.collection.InsertOne(context.TODO(), key); err != nil {
+		return domain.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetByID(id string) (domain.APIKey, error) {
+	return r.findOne(bson.M{"_id": id})
+}
+
+func (r *apiKeyRepository) GetByHash(hash string) (domain.APIKey, error) {
+	return r.findOne(bson.M{"key_hash": hash})
+}
+
+func (r *apiKeyRepository) findOne(filter bson.M) (domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.APIKey{}, ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) Get(userID string) ([]domain.APIKey, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := r.collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	keys := []domain.APIKey{}
+	if err := cursor.All(context.TODO(), &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(id string, at time.Time) error {
+	filter := bson.M{"_id": id, "revoked_at": time.Time{}}
+	result, err := r.collection.UpdateOne(context.TODO(), filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) RevokeByUser(userID string, at time.Time) error {
+	filter := bson.M{"user_id": userID, "revoked_at": time.Time{}}
+	_, err := r.collection.UpdateMany(context.TODO(), filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	return err
+}
+
+func (r *apiKeyRepository) Touch(id, ip string, at time.Time) error {
+	update := bson.M{"$set": bson.M{"last_used_at": at, "last_used_ip": ip}}
+	_, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+	return err
+}
+
+func (r *apiKeyRepository) CountRequest(id string, window time.Time) (int, error) {
+	update := bson.M{
+		"$inc":         bson.M{"count": 1},
+		"$setOnInsert": bson.M{"expires_at": window.Add(2 * time.Minute)},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var usage struct {
+		Count int `bson:"count"`
+	}
+	filter := bson.M{"_id": id + ":" + window.UTC().Format(time.RFC3339)}
+	if err := r.usageCollection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&usage); err != nil {
+		return 0, err
+	}
+	return usage.Count, nil
+}
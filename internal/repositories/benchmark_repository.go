@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrBenchmarkRateNotFound = errors.New("benchmark rate not found")
+
+type benchmarkRateRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewBenchmarkRateRepository(db mongoifc.Database) domain.BenchmarkRateRepository {
+	c := db.Collection(domain.BenchmarkRateCollection)
+	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "benchmark", Value: 1}, {Key: "effective_date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return &benchmarkRateRepository{collection: c}
+}
+
+func (r *benchmarkRateRepository) Create(rate domain.BenchmarkRate) (domain.BenchmarkRate, error) {
+	rate.ID = primitive.NewObjectID().Hex()
+	_, err := r.collection.InsertOne(context.TODO(), rate)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.BenchmarkRate{}, fmt.Errorf("a %s rate already exists for this date", rate.Benchmark)
+		}
+		return domain.BenchmarkRate{}, err
+	}
+	return rate, nil
+}
+
+func (r *benchmarkRateRepository) Delete(id string) error {
+	result, err := r.collection.DeleteOne(context.TODO(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrBenchmarkRateNotFound
+	}
+	return nil
+}
+
+func (r *benchmarkRateRepository) GetByBenchmark(benchmark string) ([]domain.BenchmarkRate, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "effective_date", Value: -1}})
+	cursor, err := r.collection.Find(context.TODO(), bson.M{"benchmark": benchmark}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	rates := []domain.BenchmarkRate{}
+	if err := cursor.All(context.TODO(), &rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// GetEffective returns the latest rate of the benchmark that took effect on or
+// before the given date.
+func (r *benchmarkRateRepository) GetEffective(benchmark string, date time.Time) (domain.BenchmarkRate, error) {
+	var rate domain.BenchmarkRate
+	filter := bson.M{"benchmark": benchmark, "effective_date": bson.M{"$lte": date}}
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "effective_date", Value: -1}})
+	err := r.collection.FindOne(context.TODO(), filter, findOptions).Decode(&rate)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.BenchmarkRate{}, ErrBenchmarkRateNotFound
+		}
+		return domain.BenchmarkRate{}, err
+	}
+	return rate, nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"loan-management/internal/domain"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrCollateralNotFound = errors.New("collateral not found")
+
+type collateralRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewCollateralRepository(db mongoifc.Database) domain.CollateralRepository {
+	c := db.Collection(domain.CollateralCollection)
+	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.M{"loan_id": 1},
+	})
+	return &collateralRepository{collection: c}
+}
+
+func (r *collateralRepository) Create(collateral domain.Collateral) (domain.Collateral, error) {
+	collateral.ID = primitive.NewObjectID().Hex()
+	_, err := r.collection.InsertOne(context.TODO(), collateral)
+	if err != nil {
+		return domain.Collateral{}, err
+	}
+	return collateral, nil
+}
+
+func (r *collateralRepository) Update(id string, updateData domain.Collateral) (domain.Collateral, error) {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{}}
+	if updateData.Type != "" {
+		update["$set"].(bson.M)["type"] = updateData.Type
+	}
+	if updateData.Description != "" {
+		update["$set"].(bson.M)["description"] = updateData.Description
+	}
+	if updateData.AppraisedValue != 0 {
+		update["$set"].(bson.M)["appraised_value"] = updateData.AppraisedValue
+	}
+	if !updateData.ValuationDate.IsZero() {
+		update["$set"].(bson.M)["valuation_date"] = updateData.ValuationDate
+	}
+	if updateData.LienStatus != "" {
+		update["$set"].(bson.M)["lien_status"] = updateData.LienStatus
+	}
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return domain.Collateral{}, fmt.Errorf("failed to update collateral: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.Collateral{}, ErrCollateralNotFound
+	}
+	return r.GetByID(id)
+}
+
+func (r *collateralRepository) Delete(id string) error {
+	result, err := r.collection.DeleteOne(context.TODO(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrCollateralNotFound
+	}
+	return nil
+}
+
+func (r *collateralRepository) GetByID(id string) (domain.Collateral, error) {
+	var collateral domain.Collateral
+	err := r.collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&collateral)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Collateral{}, ErrCollateralNotFound
+		}
+		return domain.Collateral{}, err
+	}
+	return collateral, nil
+}
+
+func (r *collateralRepository) GetByLoanID(loanID string) ([]domain.Collateral, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(context.TODO(), bson.M{"loan_id": loanID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	collaterals := []domain.Collateral{}
+	if err := cursor.All(context.TODO(), &collaterals); err != nil {
+		return nil, err
+	}
+	return collaterals, nil
+}
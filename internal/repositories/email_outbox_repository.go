@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrOutboxEmailNotFound = errors.New("outbox email not found")
+	// ErrNoOutboxEmailDue is returned by ClaimDue when nothing is to be sent.
+	ErrNoOutboxEmailDue = errors.New("no outbox email due")
+)
+
+type emailOutboxRepository struct {
+	collection mongoifc.Collection
+	ctx        context.Context
+}
+
+func NewEmailOutboxRepository(db mongoifc.Database) domain.EmailOutboxRepository {
+	c := db.Collection(domain.EmailOutboxCollection)
+	c.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "claimed_until", Value: 1}}},
+		{Keys: bson.M{"to": 1}},
+		{Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return &emailOutboxRepository{collection: c, ctx: context.TODO()}
+}
+
+func (r *emailOutboxRepository) WithContext(ctx context.Context) domain.EmailOutboxRepository {
+	return &emailOutboxRepository{collection: r.collection, ctx: ctx}
+}
+
+func (r *emailOutboxRepository) Create(email domain.OutboxEmail) (domain.OutboxEmail, error) {
+	email.ID = primitive.NewObjectID().Hex()
+	if _, err := r.collection.InsertOne(r.ctx, email); err != nil {
+		return domain.OutboxEmail{}, err
+	}
+	return email, nil
+}
+
+func (r *emailOutboxRepository) GetByID(id string) (domain.OutboxEmail, error) {
+	var email domain.OutboxEmail
+	err := r.collection.FindOne(r.ctx, bson.M{"_id": id}).Decode(&email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.OutboxEmail{}, ErrOutboxEmailNotFound
+		}
+		return domain.OutboxEmail{}, err
+	}
+	return email, nil
+}
+
+func (r *emailOutboxRepository) GetByStatus(status string) ([]domain.OutboxEmail, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := r.collection.Find(r.ctx, bson.M{"status": status}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(r.ctx)
+
+	emails := []domain.OutboxEmail{}
+	if err := cursor.All(r.ctx, &emails); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+func (r *emailOutboxRepository) ClaimDue(now, claimedUntil time.Time) (domain.OutboxEmail, error) {
+	filter := bson.M{"$or": []bson.M{
+		{"status": domain.OutboxStatusPending, "next_attempt_at": bson.M{"$lte": now}},
+		{"status": domain.OutboxStatusSending, "claimed_until": bson.M{"$lte": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": domain.OutboxStatusSending, "claimed_until": claimedUntil},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"next_attempt_at": 1}).
+		SetReturnDocument(options.After)
+	var email domain.OutboxEmail
+	err := r.collection.FindOneAndUpdate(r.ctx, filter, update, opts).Decode(&email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.OutboxEmail{}, ErrNoOutboxEmailDue
+		}
+		return domain.OutboxEmail{}, err
+	}
+	return email, nil
+}
+
+func (r *emailOutboxRepository) MarkSent(id string, at time.Time) error {
+	update := bson.M{"$set": bson.M{
+		"status":     domain.OutboxStatusSent,
+		"sent_at":    at,
+		"last_error": "",
+		"expires_at": at.Add(domain.OutboxSentRetention),
+	}}
+	return r.updateClaimed(id, update)
+}
+
+func (r *emailOutboxRepository) Reschedule(id string, nextAttemptAt time.Time, lastError string) error {
+	update := bson.M{"$set": bson.M{
+		"status":          domain.OutboxStatusPending,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	}}
+	return r.updateClaimed(id, update)
+}
+
+func (r *emailOutboxRepository) MarkDead(id, lastError string) error {
+	update := bson.M{"$set": bson.M{
+		"status":     domain.OutboxStatusDead,
+		"last_error": lastError,
+	}}
+	return r.updateClaimed(id, update)
+}
+
+// updateClaimed updates an email that is still claimed for sending.
+func (r *emailOutboxRepository) updateClaimed(id string, update bson.M) error {
+	filter := bson.M{"_id": id, "status": domain.OutboxStatusSending}
+	result, err := r.collection.UpdateOne(r.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrOutboxEmailNotFound
+	}
+	return nil
+}
+
+func (r *emailOutboxRepository) Requeue(id string, now time.Time) (domain.OutboxEmail, error) {
+	filter := bson.M{"_id": id, "status": domain.OutboxStatusDead}
+	update := bson.M{"$set": bson.M{
+		"status":          domain.OutboxStatusPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var email domain.OutboxEmail
+	err := r.collection.FindOneAndUpdate(r.ctx, filter, update, opts).Decode(&email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.OutboxEmail{}, ErrOutboxEmailNotFound
+		}
+		return domain.OutboxEmail{}, err
+	}
+	return email, nil
+}
+
+func (r *emailOutboxRepository) DeleteByRecipient(address string) (int64, error) {
+	result, err := r.collection.DeleteMany(r.ctx, bson.M{"to": address})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
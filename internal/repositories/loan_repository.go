@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"loan-management/internal/domain"
+	"time"
 
 	"github.com/sv-tools/mongoifc"
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,19 +14,28 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrLoanStatusChanged is returned by TransitionStatus when the loan is no
+// longer in the status it was expected to leave.
+var ErrLoanStatusChanged = errors.New("loan status has changed")
+
 type loanRepository struct {
 	collection mongoifc.Collection
+	ctx        context.Context
 }
 
 func NewLoanRepository(db mongoifc.Database) domain.LoanRepository {
 	collection := db.Collection(domain.LoanColletion)
-	return &loanRepository{collection: collection}
+	return &loanRepository{collection: collection, ctx: context.TODO()}
+}
+
+func (r *loanRepository) WithContext(ctx context.Context) domain.LoanRepository {
+	return &loanRepository{collection: r.collection, ctx: ctx}
 }
 
 func (r *loanRepository) GetByID(id string) (domain.Loan, error) {
 	var loan domain.Loan
 	filter := bson.M{"_id": id}
-	err := r.collection.FindOne(context.TODO(), filter).Decode(&loan)
+	err := r.collection.FindOne(r.ctx, filter).Decode(&loan)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return domain.Loan{}, ErrUserNotFound
@@ -46,7 +57,7 @@ func (r *loanRepository) Get(filter map[string]string) ([]domain.Loan, error) {
 	}
 	order, ok := filter["order"]
 	if !ok {
-		if status == "pending" {
+		if status == domain.LoanStatusPending {
 			order = "asc"
 		} else {
 			order = "desc"
@@ -57,14 +68,14 @@ func (r *loanRepository) Get(filter map[string]string) ([]domain.Loan, error) {
 		sortOrder = -1
 	}
 	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: sortOrder}})
-	cursor, err := r.collection.Find(context.TODO(), filterOptions, findOptions)
+	cursor, err := r.collection.Find(r.ctx, filterOptions, findOptions)
 	if err != nil {
 		return []domain.Loan{}, err
 	}
-	defer cursor.Close(context.TODO())
+	defer cursor.Close(r.ctx)
 
 	var loans []domain.Loan
-	for cursor.Next(context.TODO()) {
+	for cursor.Next(r.ctx) {
 		var l domain.Loan
 		if err := cursor.Decode(&l); err != nil {
 			return loans, nil
@@ -77,7 +88,7 @@ func (r *loanRepository) Get(filter map[string]string) ([]domain.Loan, error) {
 func (r *loanRepository) Delete(loanID string) error {
 	filter := bson.M{"_id": loanID}
 
-	result, err := r.collection.DeleteOne(context.TODO(), filter)
+	result, err := r.collection.DeleteOne(r.ctx, filter)
 	if err != nil {
 		return err
 	}
@@ -98,10 +109,46 @@ func (r *loanRepository) Update(id string, updateData domain.Loan) (domain.Loan,
 	if updateData.Ammount != "" {
 		update["$set"].(bson.M)["amount"] = updateData.Ammount
 	}
-	if updateData.Status != "" {
-		update["$set"].(bson.M)["status"] = updateData.Status
+	if updateData.ProductID != "" {
+		update["$set"].(bson.M)["product_id"] = updateData.ProductID
+	}
+	if updateData.TermMonths != 0 {
+		update["$set"].(bson.M)["term_months"] = updateData.TermMonths
+	}
+	if updateData.InterestRate != 0 {
+		update["$set"].(bson.M)["interest_rate"] = updateData.InterestRate
+	}
+	if updateData.Fees != 0 {
+		update["$set"].(bson.M)["fees"] = updateData.Fees
+	}
+	if updateData.DayCountConvention != "" {
+		update["$set"].(bson.M)["day_count_convention"] = updateData.DayCountConvention
+	}
+	if !updateData.ApprovedAt.IsZero() {
+		update["$set"].(bson.M)["approved_at"] = updateData.ApprovedAt
 	}
-	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if updateData.AccruedInterest != 0 {
+		update["$set"].(bson.M)["accrued_interest"] = updateData.AccruedInterest
+	}
+	if !updateData.AccruedThrough.IsZero() {
+		update["$set"].(bson.M)["accrued_through"] = updateData.AccruedThrough
+	}
+	if !updateData.AcceptedAt.IsZero() {
+		update["$set"].(bson.M)["accepted_at"] = updateData.AcceptedAt
+	}
+	if !updateData.WrittenOffAt.IsZero() {
+		update["$set"].(bson.M)["written_off_at"] = updateData.WrittenOffAt
+	}
+	if updateData.Offer != nil {
+		update["$set"].(bson.M)["offer"] = updateData.Offer
+	}
+	if !updateData.NextRateReset.IsZero() {
+		update["$set"].(bson.M)["next_rate_reset"] = updateData.NextRateReset
+	}
+	if updateData.Schedule != nil {
+		update["$set"].(bson.M)["schedule"] = updateData.Schedule
+	}
+	_, err := r.collection.UpdateOne(r.ctx, filter, update)
 	if err != nil {
 		return domain.Loan{}, fmt.Errorf("failed to update loan: %v", err)
 	}
@@ -117,10 +164,61 @@ func (r *loanRepository) Update(id string, updateData domain.Loan) (domain.Loan,
 func (r *loanRepository) Create(loan domain.Loan) (domain.Loan, error) {
 	loan.ID = primitive.NewObjectID().Hex()
 
-	_, err := r.collection.InsertOne(context.TODO(), loan)
+	_, err := r.collection.InsertOne(r.ctx, loan)
 	if err != nil {
 		return domain.Loan{}, err
 	}
 
 	return loan, nil
 }
+
+func (r *loanRepository) CountByProduct(productID string) (int64, error) {
+	return r.collection.CountDocuments(r.ctx, bson.M{"product_id": productID})
+}
+
+func (r *loanRepository) MarkInstallmentPaid(id string, number int, at time.Time) error {
+	// Installments scheduled before payments were recorded have no paid_at,
+	// which matches null.
+	unpaid := bson.M{"$in": bson.A{time.Time{}, nil}}
+	filter := bson.M{
+		"_id":      id,
+		"schedule": bson.M{"$elemMatch": bson.M{"number": number, "paid_at": unpaid}},
+	}
+	update := bson.M{"$set": bson.M{"schedule.$.paid_at": at}}
+	result, err := r.collection.UpdateOne(r.ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("installment %d not found or already paid", number)
+	}
+	return nil
+}
+
+// TransitionStatus moves the loan from one status to another in a single
+// write, so that of two concurrent transitions only one succeeds.
+func (r *loanRepository) TransitionStatus(id, from, to string) error {
+	filter := bson.M{"_id": id, "status": from}
+	result, err := r.collection.UpdateOne(r.ctx, filter, bson.M{"$set": bson.M{"status": to}})
+	if err != nil {
+		return fmt.Errorf("failed to update loan: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrLoanStatusChanged
+	}
+	return nil
+}
+
+// SetAccruedInterest writes the amount even when it is zero, which Update
+// would skip.
+func (r *loanRepository) SetAccruedInterest(id string, amount float64, through time.Time) error {
+	update := bson.M{"$set": bson.M{"accrued_interest": amount, "accrued_through": through}}
+	result, err := r.collection.UpdateOne(r.ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update loan: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("loan not found")
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type loginThrottleRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewLoginThrottleRepository(db mongoifc.Database) domain.LoginThrottleRepository {
+	c := db.Collection(domain.LoginThrottleCollection)
+	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"expires_at": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &loginThrottleRepository{collection: c}
+}
+
+func (r *loginThrottleRepository) Get(key string) (domain.LoginThrottle, error) {
+	var throttle domain.LoginThrottle
+	err := r.collection.FindOne(context.TODO(), bson.M{"_id": key}).Decode(&throttle)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.LoginThrottle{Key: key}, nil
+		}
+		return domain.LoginThrottle{}, err
+	}
+	return throttle, nil
+}
+
+// RecordFailure atomically counts a failed attempt, restarting the count
+// when the previous failure fell outside the window.
+func (r *loginThrottleRepository) RecordFailure(key string, at time.Time, window time.Duration) (domain.LoginThrottle, error) {
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failures": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$last_failure_at", at.Add(-window)}},
+			bson.M{"$add": bson.A{"$failures", 1}},
+			1,
+		}},
+		"last_failure_at": at,
+		"expires_at":      bson.M{"$max": bson.A{"$locked_until", at.Add(window)}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var throttle domain.LoginThrottle
+	err := r.collection.FindOneAndUpdate(context.TODO(), bson.M{"_id": key}, update, opts).Decode(&throttle)
+	if err != nil {
+		return domain.LoginThrottle{}, err
+	}
+	return throttle, nil
+}
+
+func (r *loginThrottleRepository) Lock(key string, until time.Time) error {
+	update := bson.M{"$set": bson.M{"locked_until": until, "expires_at": until}}
+	_, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": key}, update)
+	return err
+}
+
+func (r *loginThrottleRepository) Reset(key string) error {
+	_, err := r.collection.DeleteOne(context.TODO(), bson.M{"_id": key})
+	return err
+}
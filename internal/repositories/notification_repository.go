@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	// ErrNotificationExists is returned when the event was already notified.
+	ErrNotificationExists = errors.New("notification already sent")
+)
+
+type notificationRepository struct {
+	collection mongoifc.Collection
+	ctx        context.Context
+}
+
+func NewNotificationRepository(db mongoifc.Database) domain.NotificationRepository {
+	c := db.Collection(domain.NotificationCollection)
+	c.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"key": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return &notificationRepository{collection: c, ctx: context.TODO()}
+}
+
+func (r *notificationRepository) WithContext(ctx context.Context) domain.NotificationRepository {
+	return &notificationRepository{collection: r.collection, ctx: ctx}
+}
+
+// Create upserts on the key instead of inserting, so an event that was
+// already notified is detected without a duplicate key error. Such a write
+// error would abort the transaction the notification is part of.
+func (r *notificationRepository) Create(notification domain.Notification) (domain.Notification, error) {
+	notification.ID = primitive.NewObjectID().Hex()
+	filter := bson.M{"key": notification.Key}
+	update := bson.M{"$setOnInsert": notification}
+	result, err := r.collection.UpdateOne(r.ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.Notification{}, ErrNotificationExists
+		}
+		return domain.Notification{}, err
+	}
+	if result.UpsertedCount == 0 {
+		return domain.Notification{}, ErrNotificationExists
+	}
+	return notification, nil
+}
+
+func (r *notificationRepository) GetByUser(userID string) ([]domain.Notification, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := r.collection.Find(r.ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(r.ctx)
+
+	notifications := []domain.Notification{}
+	if err := cursor.All(r.ctx, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) MarkRead(userID, id string, at time.Time) error {
+	filter := bson.M{"_id": id, "user_id": userID}
+	result, err := r.collection.UpdateOne(r.ctx, filter, bson.M{"$set": bson.M{"read_at": at}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
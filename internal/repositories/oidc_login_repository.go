@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrOIDCLoginNotFound = errors.New("login request is invalid or has expired")
+
+type oidcLoginRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewOIDCLoginRepository(db mongoifc.Database) domain.OIDCLoginRepository {
+	c := db.Collection(domain.OIDCLoginCollection)
+	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"expires_at": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &oidcLoginRepository{collection: c}
+}
+
+func (r *oidcLoginRepository) Create(login domain.OIDCLogin) error {
+	_, err := r.collection.InsertOne(context.TODO(), login)
+	return err
+}
+
+func (r *oidcLoginRepository) Consume(state string) (domain.OIDCLogin, error) {
+	filter := bson.M{"_id": state, "expires_at": bson.M{"$gt": time.Now()}}
+	var login domain.OIDCLogin
+	err := r.collection.FindOneAndDelete(context.TODO(), filter).Decode(&login)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.OIDCLogin{}, ErrOIDCLoginNotFound
+		}
+		return domain.OIDCLogin{}, err
+	}
+	return login, nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrInvalidOneTimeToken = errors.New("invalid or expired token")
+
+type oneTimeTokenRepository struct {
+	collection mongoifc.Collection
+	ctx        context.Context
+}
+
+func NewOneTimeTokenRepository(db mongoifc.Database) domain.OneTimeTokenRepository {
+	c := db.Collection(domain.OneTimeTokenCollection)
+	c.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"token_hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return &oneTimeTokenRepository{collection: c, ctx: context.TODO()}
+}
+
+func (r *oneTimeTokenRepository) WithContext(ctx context.Context) domain.OneTimeTokenRepository {
+	return &oneTimeTokenRepository{collection: r.collection, ctx: ctx}
+}
+
+func (r *oneTimeTokenRepository) Create(token domain.OneTimeToken) (domain.OneTimeToken, error) {
+	token.ID = primitive.NewObjectID().Hex()
+	if _, err := r.collection.InsertOne(r.ctx, token); err != nil {
+		return domain.OneTimeToken{}, err
+	}
+	return token, nil
+}
+
+func (r *oneTimeTokenRepository) Find(hash, purpose string) (domain.OneTimeToken, error) {
+	filter := bson.M{
+		"token_hash": hash,
+		"purpose":    purpose,
+		"used_at":    time.Time{},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	var token domain.OneTimeToken
+	err := r.collection.FindOne(r.ctx, filter).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.OneTimeToken{}, ErrInvalidOneTimeToken
+		}
+		return domain.OneTimeToken{}, err
+	}
+	return token, nil
+}
+
+func (r *oneTimeTokenRepository) Consume(hash, purpose string) (domain.OneTimeToken, error) {
+	now := time.Now()
+	filter := bson.M{
+		"token_hash": hash,
+		"purpose":    purpose,
+		"used_at":    time.Time{},
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var token domain.OneTimeToken
+	err := r.collection.FindOneAndUpdate(r.ctx, filter, update, opts).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.OneTimeToken{}, ErrInvalidOneTimeToken
+		}
+		return domain.OneTimeToken{}, err
+	}
+	return token, nil
+}
+
+func (r *oneTimeTokenRepository) Invalidate(userID, purpose string) error {
+	filter := bson.M{"user_id": userID, "purpose": purpose, "used_at": time.Time{}}
+	_, err := r.collection.UpdateMany(r.ctx, filter, bson.M{"$set": bson.M{"used_at": time.Now()}})
+	return err
+}
+
+func (r *oneTimeTokenRepository) CountIssuedSince(userID, purpose string, since time.Time) (int64, error) {
+	filter := bson.M{"user_id": userID, "purpose": purpose, "created_at": bson.M{"$gte": since}}
+	return r.collection.CountDocuments(r.ctx, filter)
+}
+
+func (r *oneTimeTokenRepository) GetLatest(userID, purpose string) (domain.OneTimeToken, error) {
+	filter := bson.M{"user_id": userID, "purpose": purpose}
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	var token domain.OneTimeToken
+	err := r.collection.FindOne(r.ctx, filter, opts).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.OneTimeToken{}, nil
+		}
+		return domain.OneTimeToken{}, err
+	}
+	return token, nil
+}
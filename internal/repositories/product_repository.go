@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"loan-management/internal/domain"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrProductNotFound = errors.New("loan product not found")
+
+type loanProductRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewLoanProductRepository(db mongoifc.Database) domain.LoanProductRepository {
+	c := db.Collection(domain.LoanProductCollection)
+	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"name": 1},
+		Options: options.Index().SetUnique(true),
+	})
+	return &loanProductRepository{collection: c}
+}
+
+func (r *loanProductRepository) Create(product domain.LoanProduct) (domain.LoanProduct, error) {
+	product.ID = primitive.NewObjectID().Hex()
+	_, err := r.collection.InsertOne(context.TODO(), product)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.LoanProduct{}, fmt.Errorf("loan product exists with the given name")
+		}
+		return domain.LoanProduct{}, err
+	}
+	return product, nil
+}
+
+func (r *loanProductRepository) Update(id string, updateData domain.LoanProduct) (domain.LoanProduct, error) {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{}}
+	if updateData.Name != "" {
+		update["$set"].(bson.M)["name"] = updateData.Name
+	}
+	if updateData.RateType != "" {
+		update["$set"].(bson.M)["rate_type"] = updateData.RateType
+	}
+	if updateData.FixedRate != 0 {
+		update["$set"].(bson.M)["fixed_rate"] = updateData.FixedRate
+	}
+	if updateData.Benchmark != "" {
+		update["$set"].(bson.M)["benchmark"] = updateData.Benchmark
+	}
+	if updateData.Margin != 0 {
+		update["$set"].(bson.M)["margin"] = updateData.Margin
+	}
+	if updateData.ResetFrequencyMonths != 0 {
+		update["$set"].(bson.M)["reset_frequency_months"] = updateData.ResetFrequencyMonths
+	}
+	if updateData.TermMonths != 0 {
+		update["$set"].(bson.M)["term_months"] = updateData.TermMonths
+	}
+	if updateData.OriginationFee != 0 {
+		update["$set"].(bson.M)["origination_fee"] = updateData.OriginationFee
+	}
+	if updateData.OriginationFeeRate != 0 {
+		update["$set"].(bson.M)["origination_fee_rate"] = updateData.OriginationFeeRate
+	}
+	if updateData.DayCountConvention != "" {
+		update["$set"].(bson.M)["day_count_convention"] = updateData.DayCountConvention
+	}
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return domain.LoanProduct{}, fmt.Errorf("failed to update loan product: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.LoanProduct{}, ErrProductNotFound
+	}
+	return r.GetByID(id)
+}
+
+func (r *loanProductRepository) Delete(id string) error {
+	result, err := r.collection.DeleteOne(context.TODO(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+func (r *loanProductRepository) Get() ([]domain.LoanProduct, error) {
+	cursor, err := r.collection.Find(context.TODO(), bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	products := []domain.LoanProduct{}
+	if err := cursor.All(context.TODO(), &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *loanProductRepository) GetByID(id string) (domain.LoanProduct, error) {
+	var product domain.LoanProduct
+	err := r.collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&product)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.LoanProduct{}, ErrProductNotFound
+		}
+		return domain.LoanProduct{}, err
+	}
+	return product, nil
+}
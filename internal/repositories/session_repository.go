@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrRefreshTokenNotFound = errors.New("invalid refresh token")
+)
+
+type sessionRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewSessionRepository(db mongoifc.Database) domain.SessionRepository {
+	c := db.Collection(domain.SessionCollection)
+	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.M{"user_id": 1},
+	})
+	return &sessionRepository{collection: c}
+}
+
+func (r *sessionRepository) Create(session domain.Session) (domain.Session, error) {
+	session.ID = primitive.NewObjectID().Hex()
+	_, err := r.collection.InsertOne(context.TODO(), session)
+	if err != nil {
+		return domain.Session{}, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) GetByID(id string) (domain.Session, error) {
+	var session domain.Session
+	err := r.collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Session{}, ErrSessionNotFound
+		}
+		return domain.Session{}, err
+	}
+	return session, nil
+}
+
+// GetActiveByUserID returns the sessions of a user that are neither revoked
+// nor expired, most recently used first.
+func (r *sessionRepository) GetActiveByUserID(userID string) ([]domain.Session, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": time.Time{},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}})
+	cursor, err := r.collection.Find(context.TODO(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	sessions := []domain.Session{}
+	if err := cursor.All(context.TODO(), &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) Touch(id, ip string) error {
+	update := bson.M{"$set": bson.M{"last_used_at": time.Now(), "ip": ip}}
+	_, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+	return err
+}
+
+func (r *sessionRepository) Revoke(id string) error {
+	filter := bson.M{"_id": id, "revoked_at": time.Time{}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	return err
+}
+
+func (r *sessionRepository) RevokeByUserID(userID, exceptID string) error {
+	filter := bson.M{"user_id": userID, "revoked_at": time.Time{}}
+	if exceptID != "" {
+		filter["_id"] = bson.M{"$ne": exceptID}
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+	_, err := r.collection.UpdateMany(context.TODO(), filter, update)
+	return err
+}
+
+type refreshTokenRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewRefreshTokenRepository(db mongoifc.Database) domain.RefreshTokenRepository {
+	c := db.Collection(domain.RefreshTokenCollection)
+	c.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"token_hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"session_id": 1}},
+	})
+	return &refreshTokenRepository{collection: c}
+}
+
+func (r *refreshTokenRepository) Create(token domain.RefreshToken) (domain.RefreshToken, error) {
+	token.ID = primitive.NewObjectID().Hex()
+	_, err := r.collection.InsertOne(context.TODO(), token)
+	if err != nil {
+		return domain.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (r *refreshTokenRepository) GetByHash(hash string) (domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.collection.FindOne(context.TODO(), bson.M{"token_hash": hash}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.RefreshToken{}, ErrRefreshTokenNotFound
+		}
+		return domain.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+// MarkUsed consumes a refresh token. It reports false when the token had
+// already been consumed, including by a concurrent request.
+func (r *refreshTokenRepository) MarkUsed(id string) (bool, error) {
+	filter := bson.M{"_id": id, "used_at": time.Time{}}
+	update := bson.M{"$set": bson.M{"used_at": time.Now()}}
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *refreshTokenRepository) DeleteBySessionID(sessionID string) error {
+	_, err := r.collection.DeleteMany(context.TODO(), bson.M{"session_id": sessionID})
+	return err
+}
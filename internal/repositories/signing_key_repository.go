@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"loan-management/internal/domain"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type signingKeyRepository struct {
+	collection mongoifc.Collection
+}
+
+func NewSigningKeyRepository(db mongoifc.Database) domain.SigningKeyRepository {
+	return &signingKeyRepository{collection: db.Collection(domain.SigningKeyCollection)}
+}
+
+func (r *signingKeyRepository) Create(key domain.SigningKey) (domain.SigningKey, error) {
+	key.ID = primitive.NewObjectID().Hex()
+	_, err := r.collection.InsertOne(context.TODO(), key)
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	return key, nil
+}
+
+// GetValid returns the keys that can still verify tokens, newest first.
+func (r *signingKeyRepository) GetValid() ([]domain.SigningKey, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"retired_at": time.Time{}},
+		bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+	}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(context.TODO(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	keys := []domain.SigningKey{}
+	if err := cursor.All(context.TODO(), &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *signingKeyRepository) Retire(id string, retiredAt, expiresAt time.Time) error {
+	filter := bson.M{"_id": id, "retired_at": time.Time{}}
+	update := bson.M{"$set": bson.M{"retired_at": retiredAt, "expires_at": expiresAt}}
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	return err
+}
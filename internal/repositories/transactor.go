@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"loan-management/internal/domain"
+	"log"
+	"sync"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type transactor struct {
+	db           mongoifc.Database
+	mu           sync.Mutex
+	checked      bool
+	transactions bool
+}
+
+func NewTransactor(db mongoifc.Database) domain.Transactor {
+	return &transactor{db: db}
+}
+
+// WithTransaction runs fn in a transaction, retrying it on transient errors.
+// Transactions need a replica set or sharded cluster; on a standalone server,
+// as used in development, fn runs without one.
+func (t *transactor) WithTransaction(fn func(ctx context.Context) error) error {
+	supported, err := t.supportsTransactions()
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fn(context.TODO())
+	}
+
+	session, err := t.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(context.Background(), func(sc mongoifc.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+func (t *transactor) supportsTransactions() (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.checked {
+		return t.transactions, nil
+	}
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := t.db.RunCommand(context.TODO(), bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, err
+	}
+	t.checked = true
+	t.transactions = hello.SetName != "" || hello.Msg == "isdbgrid"
+	if !t.transactions {
+		log.Printf("database %s is a standalone server without transactions, related writes will not be atomic", t.db.Name())
+	}
+	return t.transactions, nil
+}
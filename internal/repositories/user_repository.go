@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"loan-management/internal/domain"
+	"time"
 
 	"github.com/sv-tools/mongoifc"
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,22 +23,28 @@ var (
 
 type userRepository struct {
 	collection mongoifc.Collection
+	ctx        context.Context
 }
 
 func NewUserRepository(db mongoifc.Database) domain.UserRepository {
 	c := db.Collection(domain.UserCollection)
-	c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
-		Keys:    bson.M{"email": 1},
-		Options: options.Index().SetUnique(true),
+	c.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"email": 1}, Options: options.Index().SetUnique(true)},
+		{
+			Keys: bson.D{{Key: "external_identity.issuer", Value: 1}, {Key: "external_identity.subject", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"external_identity.subject": bson.M{"$gt": ""}}),
+		},
 	})
-	return &userRepository{collection: c}
+	return &userRepository{collection: c, ctx: context.TODO()}
+}
+
+func (r *userRepository) WithContext(ctx context.Context) domain.UserRepository {
+	return &userRepository{collection: r.collection, ctx: ctx}
 }
 
 func (r *userRepository) Create(user domain.User) (domain.User, error) {
-	if cnt, _ := r.collection.CountDocuments(context.TODO(), bson.M{}); cnt == 0 {
-		user.IsAdmin = true
-	}
-	_, err := r.collection.InsertOne(context.TODO(), user)
+	_, err := r.collection.InsertOne(r.ctx, user)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
 			return domain.User{}, fmt.Errorf("user exists with the give email")
@@ -47,28 +54,52 @@ func (r *userRepository) Create(user domain.User) (domain.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) Update(id string, updateData domain.User) (domain.User, error) {
+func (r *userRepository) Update(id string, updateData domain.UserUpdate) (domain.User, error) {
 	filter := bson.M{"_id": id}
 	update := bson.M{"$set": bson.M{}}
-	if updateData.Email != "" {
-		return domain.User{}, fmt.Errorf("updating email is not allowed")
+	if updateData.Email != nil {
+		update["$set"].(bson.M)["email"] = *updateData.Email
+	}
+	if updateData.Name != nil {
+		update["$set"].(bson.M)["name"] = *updateData.Name
+	}
+	if updateData.Phone != nil {
+		update["$set"].(bson.M)["phone"] = *updateData.Phone
 	}
-	if updateData.Name != "" {
-		update["$set"].(bson.M)["name"] = updateData.Name
+	if updateData.Address != nil {
+		update["$set"].(bson.M)["address"] = *updateData.Address
 	}
-	if updateData.Password != "" {
-		update["$set"].(bson.M)["password"] = updateData.Password
+	if updateData.Language != nil {
+		update["$set"].(bson.M)["language"] = *updateData.Language
 	}
-	if updateData.IsActive {
-		update["$set"].(bson.M)["is_active"] = updateData.IsActive
+	if updateData.Password != nil {
+		update["$set"].(bson.M)["password"] = *updateData.Password
 	}
-	if updateData.IsAdmin {
-		update["$set"].(bson.M)["is_admin"] = updateData.IsAdmin
+	if updateData.IsActive != nil {
+		update["$set"].(bson.M)["is_active"] = *updateData.IsActive
 	}
-	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if updateData.IsAdmin != nil {
+		update["$set"].(bson.M)["is_admin"] = *updateData.IsAdmin
+	}
+	if updateData.PasswordHistory != nil {
+		update["$set"].(bson.M)["password_history"] = *updateData.PasswordHistory
+	}
+	if updateData.PasswordResetRequired != nil {
+		update["$set"].(bson.M)["password_reset_required"] = *updateData.PasswordResetRequired
+	}
+	if len(update["$set"].(bson.M)) == 0 {
+		return r.GetByID(id)
+	}
+	result, err := r.collection.UpdateOne(r.ctx, filter, update)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.User{}, fmt.Errorf("user exists with the give email")
+		}
 		return domain.User{}, ErrFailedToUpdate
 	}
+	if result.MatchedCount == 0 {
+		return domain.User{}, ErrUserNotFound
+	}
 	updatedUser, err := r.GetByID(id)
 	if err != nil {
 		return domain.User{}, ErrFailedToRetrieve
@@ -78,22 +109,25 @@ func (r *userRepository) Update(id string, updateData domain.User) (domain.User,
 
 func (r *userRepository) Delete(id string) error {
 	filter := bson.M{"_id": id}
-	_, err := r.collection.DeleteOne(context.TODO(), filter)
+	result, err := r.collection.DeleteOne(r.ctx, filter)
 	if err != nil {
 		return ErrFailedToDelete
 	}
+	if result.DeletedCount == 0 {
+		return ErrUserNotFound
+	}
 	return nil
 }
 
 func (r *userRepository) Get() ([]domain.User, error) {
-	cursor, err := r.collection.Find(context.TODO(), bson.M{})
+	cursor, err := r.collection.Find(r.ctx, bson.M{})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.TODO())
+	defer cursor.Close(r.ctx)
 
 	var users []domain.User
-	for cursor.Next(context.TODO()) {
+	for cursor.Next(r.ctx) {
 		var user domain.User
 		if err = cursor.Decode(&user); err != nil {
 			return nil, err
@@ -109,7 +143,7 @@ func (r *userRepository) Get() ([]domain.User, error) {
 func (r *userRepository) GetByID(id string) (domain.User, error) {
 	var user domain.User
 	filter := bson.M{"_id": id}
-	err := r.collection.FindOne(context.TODO(), filter).Decode(&user)
+	err := r.collection.FindOne(r.ctx, filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return domain.User{}, ErrUserNotFound
@@ -122,7 +156,7 @@ func (r *userRepository) GetByID(id string) (domain.User, error) {
 func (r *userRepository) GetByEmail(email string) (domain.User, error) {
 	var user domain.User
 	filter := bson.M{"email": email}
-	err := r.collection.FindOne(context.TODO(), filter).Decode(&user)
+	err := r.collection.FindOne(r.ctx, filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return domain.User{}, ErrUserNotFound
@@ -131,3 +165,144 @@ func (r *userRepository) GetByEmail(email string) (domain.User, error) {
 	}
 	return user, nil
 }
+
+func (r *userRepository) UpdateMFA(id string, mfa domain.MFA) error {
+	result, err := r.collection.UpdateOne(r.ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"mfa": mfa}})
+	if err != nil {
+		return ErrFailedToUpdate
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SetRoles replaces the user's roles. Users with any role are staff and keep
+// the is_admin flag in sync.
+func (r *userRepository) SetRoles(id string, roles []domain.Role) error {
+	update := bson.M{"$set": bson.M{"roles": roles, "is_admin": len(roles) > 0}}
+	result, err := r.collection.UpdateOne(r.ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return ErrFailedToUpdate
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) SetSuspendedAt(id string, at time.Time) error {
+	update := bson.M{"$set": bson.M{"suspended_at": at}}
+	result, err := r.collection.UpdateOne(r.ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return ErrFailedToUpdate
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) SetNotificationOptOuts(id string, optOuts []domain.NotificationType) error {
+	update := bson.M{"$set": bson.M{"notification_opt_outs": optOuts}}
+	result, err := r.collection.UpdateOne(r.ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return ErrFailedToUpdate
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// CountByRole counts the users holding role, as domain.EffectiveRoles sees
+// it: admins created before roles existed count as super admins.
+func (r *userRepository) CountByRole(role domain.Role) (int64, error) {
+	filter := bson.M{"roles": role}
+	if role == domain.RoleSuperAdmin {
+		noRoles := bson.M{"$in": bson.A{nil, bson.A{}}}
+		filter = bson.M{"$or": bson.A{
+			bson.M{"roles": role},
+			bson.M{"roles": noRoles, "is_admin": true},
+		}}
+	}
+	return r.collection.CountDocuments(r.ctx, filter)
+}
+
+// Anonymize erases the personal data and credentials of a user while keeping
+// the document, so records that reference the user ID stay consistent.
+func (r *userRepository) Anonymize(id string, at time.Time) error {
+	update := bson.M{"$set": bson.M{
+		"email":                   "deleted-" + id + "@deleted.invalid",
+		"name":                    "Deleted User",
+		"phone":                   "",
+		"address":                 "",
+		"language":                "",
+		"password":                "",
+		"password_history":        []string{},
+		"is_active":               false,
+		"is_admin":                false,
+		"roles":                   []domain.Role{},
+		"mfa":                     domain.MFA{},
+		"password_reset_required": false,
+		"deleted_at":              at,
+		"external_identity":       domain.ExternalIdentity{},
+	}}
+	result, err := r.collection.UpdateOne(r.ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return ErrFailedToUpdate
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted TOTP code. It reports false
+// if that step or a later one was already used, so a code cannot be replayed.
+func (r *userRepository) UseTOTPStep(id string, step int64) (bool, error) {
+	filter := bson.M{"_id": id, "mfa.last_used_step": bson.M{"$lt": step}}
+	result, err := r.collection.UpdateOne(r.ctx, filter, bson.M{"$set": bson.M{"mfa.last_used_step": step}})
+	if err != nil {
+		return false, ErrFailedToUpdate
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// ConsumeRecoveryCode removes a hashed recovery code, reporting whether the
+// user had it.
+func (r *userRepository) ConsumeRecoveryCode(id, hash string) (bool, error) {
+	filter := bson.M{"_id": id, "mfa.recovery_codes": hash}
+	result, err := r.collection.UpdateOne(r.ctx, filter, bson.M{"$pull": bson.M{"mfa.recovery_codes": hash}})
+	if err != nil {
+		return false, ErrFailedToUpdate
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *userRepository) GetByExternalIdentity(identity domain.ExternalIdentity) (domain.User, error) {
+	var user domain.User
+	filter := bson.M{"external_identity.issuer": identity.Issuer, "external_identity.subject": identity.Subject}
+	err := r.collection.FindOne(r.ctx, filter).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.User{}, ErrUserNotFound
+		}
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (r *userRepository) LinkExternalIdentity(id string, identity domain.ExternalIdentity) error {
+	result, err := r.collection.UpdateOne(r.ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"external_identity": identity}})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("identity is already linked to another user")
+		}
+		return ErrFailedToUpdate
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"fmt"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"strconv"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type interestAccrualUsecase struct {
+	accrualRepository domain.InterestAccrualRepository
+	loanRepository    domain.LoanRepository
+	logRepository     domain.LogRepository
+}
+
+func NewInterestAccrualUsecase(db mongoifc.Database) domain.InterestAccrualUsecase {
+	return &interestAccrualUsecase{
+		accrualRepository: repositories.NewInterestAccrualRepository(db),
+		loanRepository:    repositories.NewLoanRepository(db),
+		logRepository:     repositories.NewLogRepository(db),
+	}
+}
+
+// AccrueInterest records one accrual entry per loan and day for every active
+// loan, up to but not including the day of asOf. It returns the number of
+// entries created and can safely be re-run for the same day.
+func (uc *interestAccrualUsecase) AccrueInterest(asOf time.Time) (int, error) {
+	loans, err := uc.loanRepository.Get(map[string]string{"status": domain.LoanStatusAccepted})
+	if err != nil {
+		return 0, err
+	}
+	end := truncateToDay(asOf)
+	created := 0
+	for _, loan := range loans {
+		n, err := uc.accrueLoan(loan, end)
+		created += n
+		if err != nil {
+			log := domain.SystemLog{
+				ID:        primitive.NewObjectID().Hex(),
+				Timestamp: time.Now(),
+				Category:  "Interest Accrual Failure",
+				Message:   fmt.Sprintf("Interest accrual for loan %s failed: %v", loan.ID, err),
+			}
+			if err := uc.logRepository.Create(log); err != nil {
+				fmt.Printf("Failed to log interest accrual failure: %v\n", err)
+			}
+		}
+	}
+	return created, nil
+}
+
+// accrueLoan accrues each day's interest on the principal still outstanding
+// that day, then stores the interest accrued but not yet paid: everything
+// accrued less the interest portion of the installments paid so far.
+func (uc *interestAccrualUsecase) accrueLoan(loan domain.Loan, end time.Time) (int, error) {
+	principal, err := strconv.ParseFloat(loan.Ammount, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid loan amount %q", loan.Ammount)
+	}
+	if loan.DayCountConvention == "" {
+		loan.DayCountConvention = domain.DayCountActual365
+	}
+	start := loan.AccruedThrough
+	if start.IsZero() {
+		start = loan.AcceptedAt
+	}
+	if start.IsZero() {
+		return 0, fmt.Errorf("loan has no accrual start date")
+	}
+	start = truncateToDay(start)
+	if start.After(end) {
+		end = start
+	}
+
+	created := 0
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		fraction, err := dayCountFraction(loan.DayCountConvention, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return created, err
+		}
+		outstanding := outstandingPrincipal(loan.Schedule, principal, day)
+		_, err = uc.accrualRepository.Create(domain.InterestAccrual{
+			LoanID:             loan.ID,
+			Date:               day,
+			Principal:          outstanding,
+			InterestRate:       loan.InterestRate,
+			DayCountConvention: loan.DayCountConvention,
+			Amount:             outstanding * loan.InterestRate * fraction,
+			CreatedAt:          time.Now(),
+		})
+		if err != nil && err != repositories.ErrAccrualExists {
+			return created, err
+		}
+		if err == nil {
+			created++
+		}
+	}
+
+	accruals, err := uc.accrualRepository.GetByLoanID(loan.ID)
+	if err != nil {
+		return created, err
+	}
+	unpaid := 0.0
+	for _, a := range accruals {
+		unpaid += a.Amount
+	}
+	for _, installment := range loan.Schedule {
+		if !installment.PaidAt.IsZero() {
+			unpaid -= installment.Interest
+		}
+	}
+	if unpaid < 0 {
+		unpaid = 0
+	}
+	return created, uc.loanRepository.SetAccruedInterest(loan.ID, roundCents(unpaid), end)
+}
+
+// outstandingPrincipal is the principal left on day, after the principal of
+// the installments paid on or before it.
+func outstandingPrincipal(schedule []domain.Installment, principal float64, day time.Time) float64 {
+	for _, installment := range schedule {
+		if !installment.PaidAt.IsZero() && !truncateToDay(installment.PaidAt).After(day) {
+			principal -= installment.Principal
+		}
+	}
+	if principal < 0 {
+		return 0
+	}
+	return principal
+}
+
+func (uc *interestAccrualUsecase) GetLoanAccruals(loanID string) ([]domain.InterestAccrual, error) {
+	if _, err := uc.loanRepository.GetByID(loanID); err != nil {
+		return nil, err
+	}
+	return uc.accrualRepository.GetByLoanID(loanID)
+}
+
+// dayCountFraction returns the fraction of a year between start and end under
+// the given day-count convention. 30/360 follows the US (bond basis) rules.
+func dayCountFraction(convention string, start, end time.Time) (float64, error) {
+	switch convention {
+	case domain.DayCountActual365:
+		return actualDays(start, end) / 365, nil
+	case domain.DayCountActual360:
+		return actualDays(start, end) / 360, nil
+	case domain.DayCount30360:
+		y1, m1, d1 := start.Date()
+		y2, m2, d2 := end.Date()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		days := 360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1)
+		return float64(days) / 360, nil
+	}
+	return 0, fmt.Errorf("unsupported day count convention %s", convention)
+}
+
+func actualDays(start, end time.Time) float64 {
+	return truncateToDay(end).Sub(truncateToDay(start)).Hours() / 24
+}
+
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,263 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"strconv"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// actionPermissions is the permission a checker needs to approve each action;
+// it is the same permission the maker needs to request it.
+var actionPermissions = map[string]domain.Permission{
+	domain.ActionLoanApproval: domain.PermissionLoansApprove,
+	domain.ActionLoanWriteOff: domain.PermissionLoansWriteOff,
+	domain.ActionLoanDeletion: domain.PermissionLoansDelete,
+	domain.ActionRoleGrant:    domain.PermissionRolesAssign,
+}
+
+type actionRequestUsecase struct {
+	actionRequestRepository domain.ActionRequestRepository
+	loanRepository          domain.LoanRepository
+	userRepository          domain.UserRepository
+	logRepository           domain.LogRepository
+	loanUsecase             domain.LoanUsecase
+	userUsecase             domain.UserUsecases
+}
+
+func NewActionRequestUsecase(db mongoifc.Database) domain.ActionRequestUsecase {
+	return &actionRequestUsecase{
+		actionRequestRepository: repositories.NewActionRequestRepository(db),
+		loanRepository:          repositories.NewLoanRepository(db),
+		userRepository:          repositories.NewUserRepository(db),
+		logRepository:           repositories.NewLogRepository(db),
+		loanUsecase:             NewLoanUsecase(db),
+		userUsecase:             NewUserUsecase(db),
+	}
+}
+
+func (uc *actionRequestUsecase) Perform(request domain.ActionRequest) (domain.ActionRequest, error) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return domain.ActionRequest{}, err
+	}
+	dual, err := uc.requiresDualControl(config.Approval, request)
+	if err != nil {
+		return domain.ActionRequest{}, err
+	}
+	validity, err := time.ParseDuration(config.Approval.RequestValidity)
+	if err != nil || validity <= 0 {
+		validity = domain.DefaultActionRequestValidity
+	}
+
+	now := time.Now()
+	request.Status = domain.ActionStatusPending
+	request.DualControl = dual
+	request.RequestedAt = now
+	request.ExpiresAt = now.Add(validity)
+	if !dual {
+		request.Status = domain.ActionStatusApproved
+	}
+	request, err = uc.actionRequestRepository.Create(request)
+	if err != nil {
+		return domain.ActionRequest{}, err
+	}
+
+	if dual {
+		log := domain.SystemLog{
+			ID:        primitive.NewObjectID().Hex(),
+			Timestamp: time.Now(),
+			Category:  "Dual Control",
+			Message:   fmt.Sprintf("Admin %s requested %s of %s (request %s), awaiting a second admin", request.RequestedBy, request.Type, request.TargetID, request.ID),
+		}
+		if err := uc.logRepository.Create(log); err != nil {
+			fmt.Printf("Failed to log action request: %v\n", err)
+		}
+		return request, nil
+	}
+	return uc.execute(request)
+}
+
+// Approve executes a pending request on behalf of a checker. The checker must
+// be a different admin holding the permission the action needs.
+func (uc *actionRequestUsecase) Approve(id, checkerID string, checkerRoles []domain.Role) (domain.ActionRequest, error) {
+	request, err := uc.actionRequestRepository.GetByID(id)
+	if err != nil {
+		return domain.ActionRequest{}, err
+	}
+	if err := uc.checkReviewable(request, checkerID, checkerRoles); err != nil {
+		return domain.ActionRequest{}, err
+	}
+	ok, err := uc.actionRequestRepository.Review(id, domain.ActionStatusApproved, checkerID, "")
+	if err != nil {
+		return domain.ActionRequest{}, err
+	}
+	if !ok {
+		return domain.ActionRequest{}, errors.New("action request is no longer pending")
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Dual Control",
+		Message:   fmt.Sprintf("Admin %s approved %s of %s (request %s) requested by admin %s", checkerID, request.Type, request.TargetID, request.ID, request.RequestedBy),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log action approval: %v\n", err)
+	}
+
+	request.Status = domain.ActionStatusApproved
+	request.ReviewedBy = checkerID
+	return uc.execute(request)
+}
+
+// Reject closes a pending request without executing it. Like approving, it
+// takes a different admin holding the permission the action needs.
+func (uc *actionRequestUsecase) Reject(id, checkerID string, checkerRoles []domain.Role, note string) (domain.ActionRequest, error) {
+	request, err := uc.actionRequestRepository.GetByID(id)
+	if err != nil {
+		return domain.ActionRequest{}, err
+	}
+	if err := uc.checkReviewable(request, checkerID, checkerRoles); err != nil {
+		return domain.ActionRequest{}, err
+	}
+	ok, err := uc.actionRequestRepository.Review(id, domain.ActionStatusRejected, checkerID, note)
+	if err != nil {
+		return domain.ActionRequest{}, err
+	}
+	if !ok {
+		return domain.ActionRequest{}, errors.New("action request is no longer pending")
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Dual Control",
+		Message:   fmt.Sprintf("Admin %s rejected %s of %s (request %s) requested by admin %s", checkerID, request.Type, request.TargetID, request.ID, request.RequestedBy),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log action rejection: %v\n", err)
+	}
+
+	return uc.actionRequestRepository.GetByID(id)
+}
+
+func (uc *actionRequestUsecase) GetActionRequests(status string) ([]domain.ActionRequest, error) {
+	if _, err := uc.actionRequestRepository.ExpirePending(time.Now()); err != nil {
+		return nil, err
+	}
+	return uc.actionRequestRepository.Get(status)
+}
+
+func (uc *actionRequestUsecase) GetActionRequest(id string) (domain.ActionRequest, error) {
+	return uc.actionRequestRepository.GetByID(id)
+}
+
+func (uc *actionRequestUsecase) checkReviewable(request domain.ActionRequest, checkerID string, checkerRoles []domain.Role) error {
+	if request.Status != domain.ActionStatusPending {
+		return fmt.Errorf("action request is %s", request.Status)
+	}
+	if request.RequestedBy == checkerID {
+		return errors.New("an action request must be reviewed by a different admin")
+	}
+	if !domain.HasPermission(checkerRoles, actionPermissions[request.Type]) {
+		return fmt.Errorf("permission %s required to review this request", actionPermissions[request.Type])
+	}
+	if time.Now().After(request.ExpiresAt) {
+		if _, err := uc.actionRequestRepository.ExpirePending(time.Now()); err != nil {
+			return err
+		}
+		return errors.New("action request has expired")
+	}
+	return nil
+}
+
+// requiresDualControl validates the request and reports whether it needs a
+// second admin under the configured policy.
+func (uc *actionRequestUsecase) requiresDualControl(policy config.Approval, request domain.ActionRequest) (bool, error) {
+	switch request.Type {
+	case domain.ActionLoanApproval:
+		loan, err := uc.loanRepository.GetByID(request.TargetID)
+		if err != nil {
+			return false, err
+		}
+		if loan.Status != domain.LoanStatusPending {
+			return false, fmt.Errorf("loan status cannot be updated from %s", loan.Status)
+		}
+		amount, err := strconv.ParseFloat(loan.Ammount, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid loan amount %q", loan.Ammount)
+		}
+		return policy.LoanAmountThreshold > 0 && amount > policy.LoanAmountThreshold, nil
+	case domain.ActionLoanWriteOff:
+		loan, err := uc.loanRepository.GetByID(request.TargetID)
+		if err != nil {
+			return false, err
+		}
+		if loan.Status != domain.LoanStatusAccepted {
+			return false, fmt.Errorf("loan cannot be written off from %s", loan.Status)
+		}
+		return policy.WriteOffs, nil
+	case domain.ActionLoanDeletion:
+		if _, err := uc.loanRepository.GetByID(request.TargetID); err != nil {
+			return false, err
+		}
+		return policy.LoanDeletions, nil
+	case domain.ActionRoleGrant:
+		if request.RequestedBy == request.TargetID {
+			return false, errors.New("you cannot change your own roles")
+		}
+		user, err := uc.userRepository.GetByID(request.TargetID)
+		if err != nil {
+			return false, err
+		}
+		held := map[domain.Role]bool{}
+		for _, role := range domain.EffectiveRoles(user) {
+			held[role] = true
+		}
+		grants := false
+		for _, role := range request.Roles {
+			if !domain.ValidRole(role) {
+				return false, fmt.Errorf("unknown role %s", role)
+			}
+			if !held[role] {
+				grants = true
+			}
+		}
+		// Removing roles never needs a second admin.
+		return policy.RoleGrants && grants, nil
+	}
+	return false, fmt.Errorf("unknown action %s", request.Type)
+}
+
+// execute runs an approved request and records its outcome.
+func (uc *actionRequestUsecase) execute(request domain.ActionRequest) (domain.ActionRequest, error) {
+	var err error
+	switch request.Type {
+	case domain.ActionLoanApproval:
+		_, err = uc.loanUsecase.ApproveRejectLoan(request.TargetID, "approved")
+	case domain.ActionLoanWriteOff:
+		_, err = uc.loanUsecase.WriteOffLoan(request.TargetID, request.Reason)
+	case domain.ActionLoanDeletion:
+		err = uc.loanUsecase.DeleteLoan(request.TargetID)
+	case domain.ActionRoleGrant:
+		_, err = uc.userUsecase.AssignRoles(request.RequestedBy, request.TargetID, request.Roles)
+	}
+
+	request.Status = domain.ActionStatusExecuted
+	request.ExecutedAt = time.Now()
+	if err != nil {
+		request.Status = domain.ActionStatusFailed
+		request.Error = err.Error()
+	}
+	if outcomeErr := uc.actionRequestRepository.SetOutcome(request.ID, request.Status, request.Error); outcomeErr != nil {
+		fmt.Printf("Failed to record action request outcome: %v\n", outcomeErr)
+	}
+	return request, err
+}
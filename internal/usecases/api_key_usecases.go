@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/pkg/infrastructures"
+	"strings"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiKeyPrefix marks API keys so they are recognizable, e.g. by secret
+// scanners.
+const apiKeyPrefix = "lmk_"
+
+var (
+	// ErrInvalidAPIKey is returned for unknown, expired or revoked keys.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyRateLimited is returned once a key used up its requests for
+	// the current minute.
+	ErrAPIKeyRateLimited = errors.New("api key rate limit exceeded")
+)
+
+type apiKeyUsecase struct {
+	apiKeyRepository domain.APIKeyRepository
+	userRepository   domain.UserRepository
+	logRepository    domain.LogRepository
+}
+
+func NewAPIKeyUsecase(db mongoifc.Database) domain.APIKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepository: repositories.NewAPIKeyRepository(db),
+		userRepository:   repositories.NewUserRepository(db),
+		logRepository:    repositories.NewLogRepository(db),
+	}
+}
+
+// CreateAPIKey issues a key acting as key.UserID with the requested scopes.
+func (uc *apiKeyUsecase) CreateAPIKey(actorID string, key domain.APIKey) (domain.APIKey, string, error) {
+	if strings.TrimSpace(key.Name) == "" {
+		return domain.APIKey{}, "", errors.New("name is required")
+	}
+	if len(key.Scopes) == 0 {
+		return domain.APIKey{}, "", errors.New("at least one scope is required")
+	}
+	for _, scope := range key.Scopes {
+		if !domain.ValidAPIScopes[scope] {
+			return domain.APIKey{}, "", fmt.Errorf("unknown scope %s", scope)
+		}
+	}
+	if key.RateLimit < 0 {
+		return domain.APIKey{}, "", errors.New("rate limit cannot be negative")
+	}
+	if key.RateLimit == 0 {
+		key.RateLimit = domain.DefaultAPIKeyRateLimit
+	}
+	now := time.Now()
+	if key.ExpiresAt.IsZero() {
+		key.ExpiresAt = now.Add(domain.DefaultAPIKeyValidity)
+	}
+	if !key.ExpiresAt.After(now) {
+		return domain.APIKey{}, "", errors.New("expiry must be in the future")
+	}
+	owner, err := uc.userRepository.GetByID(key.UserID)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	if !owner.IsActive || !owner.SuspendedAt.IsZero() {
+		return domain.APIKey{}, "", errors.New("api keys can only be issued for active users")
+	}
+
+	token, _, err := infrastructures.GenerateOpaqueToken()
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	secret := apiKeyPrefix + token
+	key.Prefix = secret[:len(apiKeyPrefix)+8]
+	key.KeyHash = infrastructures.HashOpaqueToken(secret)
+	key.CreatedBy = actorID
+	key.CreatedAt = now
+	key.LastUsedAt = time.Time{}
+	key.RevokedAt = time.Time{}
+	key, err = uc.apiKeyRepository.Create(key)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	uc.log("API Key Management", fmt.Sprintf("Admin %s issued API key %s (%s) for user %s with scopes %v", actorID, key.ID, key.Name, key.UserID, key.Scopes))
+	return key, secret, nil
+}
+
+func (uc *apiKeyUsecase) GetAPIKeys(userID string) ([]domain.APIKey, error) {
+	return uc.apiKeyRepository.Get(userID)
+}
+
+func (uc *apiKeyUsecase) RevokeAPIKey(actorID, id string) error {
+	if err := uc.apiKeyRepository.Revoke(id, time.Now()); err != nil {
+		return err
+	}
+	uc.log("API Key Management", fmt.Sprintf("Admin %s revoked API key %s", actorID, id))
+	return nil
+}
+
+// Authenticate returns the key matching the presented secret. Keys of users
+// who were suspended, deactivated or deleted stop working as well.
+func (uc *apiKeyUsecase) Authenticate(secret, ip string) (domain.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return domain.APIKey{}, ErrInvalidAPIKey
+	}
+	key, err := uc.apiKeyRepository.GetByHash(infrastructures.HashOpaqueToken(secret))
+	if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		return domain.APIKey{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	now := time.Now()
+	if !key.RevokedAt.IsZero() || !now.Before(key.ExpiresAt) {
+		return domain.APIKey{}, ErrInvalidAPIKey
+	}
+	owner, err := uc.userRepository.GetByID(key.UserID)
+	if err != nil || !owner.IsActive || !owner.SuspendedAt.IsZero() || !owner.DeletedAt.IsZero() {
+		return domain.APIKey{}, ErrInvalidAPIKey
+	}
+
+	count, err := uc.apiKeyRepository.CountRequest(key.ID, now.Truncate(time.Minute))
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	if count > key.RateLimit {
+		if count == key.RateLimit+1 {
+			uc.log("API Key Usage", fmt.Sprintf("API key %s exceeded its limit of %d requests per minute from %s", key.ID, key.RateLimit, ip))
+		}
+		return domain.APIKey{}, ErrAPIKeyRateLimited
+	}
+	return key, nil
+}
+
+func (uc *apiKeyUsecase) RecordUse(key domain.APIKey, method, path, ip string, status int) {
+	if err := uc.apiKeyRepository.Touch(key.ID, ip, time.Now()); err != nil {
+		fmt.Printf("Failed to record api key use: %v\n", err)
+	}
+	uc.log("API Key Usage", fmt.Sprintf("API key %s of user %s called %s %s from %s: %d", key.ID, key.UserID, method, path, ip, status))
+}
+
+func (uc *apiKeyUsecase) log(category, message string) {
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  category,
+		Message:   message,
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log api key event: %v\n", err)
+	}
+}
@@ -0,0 +1,68 @@
+package usecases
+
+import (
+	"fmt"
+	"loan-management/internal/domain"
+	"math"
+)
+
+// calculateAPR returns the annual percentage rate of a loan using the
+// actuarial (IRR) method: the periodic rate that discounts the payments back
+// to the amount financed, multiplied by the number of periods per year.
+// Payments are assumed to fall due at the end of each regular period.
+func calculateAPR(amountFinanced float64, payments []float64, periodsPerYear int) (float64, error) {
+	if amountFinanced <= 0 {
+		return 0, fmt.Errorf("amount financed must be positive")
+	}
+	if len(payments) == 0 || periodsPerYear <= 0 {
+		return 0, fmt.Errorf("at least one payment period is required")
+	}
+	presentValue := func(rate float64) float64 {
+		pv := 0.0
+		for k, payment := range payments {
+			pv += payment / math.Pow(1+rate, float64(k+1))
+		}
+		return pv
+	}
+
+	// The present value falls as the rate rises, so bisect until the bracket
+	// is well below the precision an APR is disclosed with.
+	low, high := -0.99, 1.0
+	if presentValue(high) > amountFinanced {
+		return 0, fmt.Errorf("payments are too large to compute an APR")
+	}
+	for i := 0; i < 200 && high-low > 1e-12; i++ {
+		mid := (low + high) / 2
+		if presentValue(mid) > amountFinanced {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2 * float64(periodsPerYear), nil
+}
+
+// calculateCostOfCredit summarises the cost of a loan from its schedule.
+// Fees are paid upfront, so they reduce the amount financed.
+func calculateCostOfCredit(principal, fees float64, schedule []domain.Installment) (domain.CostOfCredit, error) {
+	cost := domain.CostOfCredit{
+		AmountFinanced: roundCents(principal - fees),
+		Fees:           roundCents(fees),
+	}
+	payments := make([]float64, 0, len(schedule))
+	for _, installment := range schedule {
+		cost.TotalInterest += installment.Interest
+		cost.TotalAmountPayable += installment.Payment
+		payments = append(payments, installment.Payment)
+	}
+	cost.TotalInterest = roundCents(cost.TotalInterest)
+	cost.TotalAmountPayable = roundCents(cost.TotalAmountPayable)
+	cost.TotalCostOfCredit = roundCents(cost.TotalInterest + cost.Fees)
+
+	apr, err := calculateAPR(cost.AmountFinanced, payments, 12)
+	if err != nil {
+		return domain.CostOfCredit{}, err
+	}
+	cost.APR = math.Round(apr*1e4) / 1e4
+	return cost, nil
+}
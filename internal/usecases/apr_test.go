@@ -0,0 +1,58 @@
+package usecases
+
+import (
+	"math"
+	"testing"
+)
+
+func repeat(payment float64, n int) []float64 {
+	payments := make([]float64, n)
+	for i := range payments {
+		payments[i] = payment
+	}
+	return payments
+}
+
+func TestCalculateAPR(t *testing.T) {
+	tests := []struct {
+		name           string
+		amountFinanced float64
+		payments       []float64
+		want           float64
+	}{
+		{"no fees equals nominal rate", 1000, append(repeat(88.85, 11), 88.84), 0.1200},
+		{"5000 over 36 months at 12% with 100 fee", 4900, repeat(166.07, 36), 0.1341},
+		{"interest free with 60 fee", 1140, repeat(100, 12), 0.0958},
+		{"10000 over 5 years at 6% with 500 fee", 9500, repeat(193.33, 60), 0.0815},
+		{"200000 over 30 years at 6% with 3000 fee", 197000, repeat(1199.10, 360), 0.0614},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateAPR(tt.amountFinanced, tt.payments, 12)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.want) > 0.00005 {
+				t.Errorf("calculateAPR() = %.6f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateAPRInvalidInput(t *testing.T) {
+	tests := []struct {
+		name           string
+		amountFinanced float64
+		payments       []float64
+	}{
+		{"zero amount financed", 0, repeat(100, 12)},
+		{"no payments", 1000, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := calculateAPR(tt.amountFinanced, tt.payments, 12); err == nil {
+				t.Errorf("calculateAPR() expected an error")
+			}
+		})
+	}
+}
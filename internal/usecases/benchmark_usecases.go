@@ -0,0 +1,175 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/pkg/infrastructures"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type benchmarkRateUsecase struct {
+	benchmarkRepository domain.BenchmarkRateRepository
+	productRepository   domain.LoanProductRepository
+	loanRepository      domain.LoanRepository
+	userRepository      domain.UserRepository
+	logRepository       domain.LogRepository
+	outboxRepository    domain.EmailOutboxRepository
+	transactor          domain.Transactor
+}
+
+func NewBenchmarkRateUsecase(db mongoifc.Database) domain.BenchmarkRateUsecase {
+	return &benchmarkRateUsecase{
+		benchmarkRepository: repositories.NewBenchmarkRateRepository(db),
+		productRepository:   repositories.NewLoanProductRepository(db),
+		loanRepository:      repositories.NewLoanRepository(db),
+		userRepository:      repositories.NewUserRepository(db),
+		logRepository:       repositories.NewLogRepository(db),
+		outboxRepository:    repositories.NewEmailOutboxRepository(db),
+		transactor:          repositories.NewTransactor(db),
+	}
+}
+
+func (uc *benchmarkRateUsecase) AddRate(rate domain.BenchmarkRate) (domain.BenchmarkRate, error) {
+	rate.EffectiveDate = truncateToDay(rate.EffectiveDate)
+	rate.CreatedAt = time.Now()
+	createdRate, err := uc.benchmarkRepository.Create(rate)
+	if err != nil {
+		return domain.BenchmarkRate{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Benchmark Rate Update",
+		Message:   fmt.Sprintf("Benchmark %s set to %.4f effective %s", rate.Benchmark, rate.Rate, rate.EffectiveDate.Format("2006-01-02")),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log benchmark rate update: %v\n", err)
+	}
+
+	return createdRate, nil
+}
+
+func (uc *benchmarkRateUsecase) DeleteRate(id string) error {
+	if err := uc.benchmarkRepository.Delete(id); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Benchmark Rate Deletion",
+		Message:   fmt.Sprintf("Benchmark rate %s was deleted", id),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log benchmark rate deletion: %v\n", err)
+	}
+
+	return nil
+}
+
+func (uc *benchmarkRateUsecase) GetRates(benchmark string) ([]domain.BenchmarkRate, error) {
+	return uc.benchmarkRepository.GetByBenchmark(benchmark)
+}
+
+// ResetRates reprices every variable-rate loan whose reset date has been
+// reached, regenerates its remaining schedule and notifies the borrower. It
+// returns the number of loans that were repriced.
+func (uc *benchmarkRateUsecase) ResetRates(asOf time.Time) (int, error) {
+	loans, err := uc.loanRepository.Get(map[string]string{"status": domain.LoanStatusAccepted})
+	if err != nil {
+		return 0, err
+	}
+	reset := 0
+	for _, loan := range loans {
+		if loan.NextRateReset.IsZero() || loan.NextRateReset.After(asOf) {
+			continue
+		}
+		if err := uc.resetLoanRate(loan, asOf); err != nil {
+			log := domain.SystemLog{
+				ID:        primitive.NewObjectID().Hex(),
+				Timestamp: time.Now(),
+				Category:  "Rate Reset Failure",
+				Message:   fmt.Sprintf("Rate reset for loan %s failed: %v", loan.ID, err),
+			}
+			if err := uc.logRepository.Create(log); err != nil {
+				fmt.Printf("Failed to log rate reset failure: %v\n", err)
+			}
+			continue
+		}
+		reset++
+	}
+	return reset, nil
+}
+
+func (uc *benchmarkRateUsecase) resetLoanRate(loan domain.Loan, asOf time.Time) error {
+	product, err := uc.productRepository.GetByID(loan.ProductID)
+	if err != nil {
+		return err
+	}
+	if product.RateType != domain.RateTypeVariable {
+		return fmt.Errorf("product %s is not variable-rate", product.ID)
+	}
+	resetDate := loan.NextRateReset
+	rate, err := productRate(product, uc.benchmarkRepository, resetDate)
+	if err != nil {
+		return err
+	}
+	schedule := regenerateSchedule(loan.Schedule, rate, resetDate)
+	nextReset := resetDate
+	for periods := 1; !nextReset.After(asOf); periods++ {
+		nextReset = addMonths(resetDate, periods*product.ResetFrequencyMonths)
+	}
+	// The borrower is told about the new payment in the same transaction, so
+	// a repriced loan is never left without its notice.
+	err = uc.transactor.WithTransaction(func(ctx context.Context) error {
+		updatedLoan, err := uc.loanRepository.WithContext(ctx).Update(loan.ID, domain.Loan{
+			InterestRate:  rate,
+			Schedule:      schedule,
+			NextRateReset: nextReset,
+		})
+		if err != nil {
+			return err
+		}
+		user, err := uc.userRepository.GetByID(loan.UserID)
+		if err != nil {
+			return err
+		}
+		// Deleted accounts have no address left to send the notice to.
+		if !user.DeletedAt.IsZero() {
+			return nil
+		}
+		payment := 0.0
+		for _, installment := range updatedLoan.Schedule {
+			if installment.DueDate.After(resetDate) {
+				payment = installment.Payment
+				break
+			}
+		}
+		message, err := infrastructures.RateResetEmail(user.Email, user.Language, loan.ID, rate, payment, resetDate)
+		if err != nil {
+			return err
+		}
+		return enqueueEmail(uc.outboxRepository.WithContext(ctx), message)
+	})
+	if err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Rate Reset",
+		Message:   fmt.Sprintf("Loan %s rate reset from %.4f to %.4f", loan.ID, loan.InterestRate, rate),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log rate reset: %v\n", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/pkg/infrastructures"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type bootstrapUsecase struct {
+	userRepository      domain.UserRepository
+	productRepository   domain.LoanProductRepository
+	benchmarkRepository domain.BenchmarkRateRepository
+	logRepository       domain.LogRepository
+	productUsecase      domain.LoanProductUsecase
+	benchmarkUsecase    domain.BenchmarkRateUsecase
+}
+
+func NewBootstrapUsecase(db mongoifc.Database) domain.BootstrapUsecase {
+	return &bootstrapUsecase{
+		userRepository:      repositories.NewUserRepository(db),
+		productRepository:   repositories.NewLoanProductRepository(db),
+		benchmarkRepository: repositories.NewBenchmarkRateRepository(db),
+		logRepository:       repositories.NewLogRepository(db),
+		productUsecase:      NewLoanProductUsecase(db),
+		benchmarkUsecase:    NewBenchmarkRateUsecase(db),
+	}
+}
+
+// CreateSuperAdmin creates the initial, already verified super admin. It
+// refuses to run once a super admin exists, so it cannot be used to take over
+// a live deployment. The admin has to enroll MFA before using admin endpoints.
+func (uc *bootstrapUsecase) CreateSuperAdmin(email, name, password string) (domain.User, error) {
+	if email == "" || password == "" {
+		return domain.User{}, errors.New("email and password are required")
+	}
+	count, err := uc.userRepository.CountByRole(domain.RoleSuperAdmin)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if count > 0 {
+		return domain.User{}, errors.New("a super admin already exists")
+	}
+	if err := checkPassword(password, domain.User{Email: email, Name: name}); err != nil {
+		return domain.User{}, err
+	}
+	hashedPassword, err := infrastructures.HashPassword(password)
+	if err != nil {
+		return domain.User{}, err
+	}
+	user := domain.User{
+		ID:       primitive.NewObjectIDFromTimestamp(time.Now()).Hex(),
+		Email:    email,
+		Name:     name,
+		Password: hashedPassword,
+		IsActive: true,
+		IsAdmin:  true,
+		Roles:    []domain.Role{domain.RoleSuperAdmin},
+	}
+	createdUser, err := uc.userRepository.Create(user)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Bootstrap",
+		Message:   fmt.Sprintf("Initial super admin %s created with email %s", createdUser.ID, createdUser.Email),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log super admin creation: %v\n", err)
+	}
+
+	return createdUser, nil
+}
+
+// SeedReferenceData creates the products and benchmark rates that do not
+// exist yet, so it can be run repeatedly. Products are matched by name and
+// rates by benchmark and effective date. It returns the number of records
+// created.
+func (uc *bootstrapUsecase) SeedReferenceData(data domain.ReferenceData) (int, error) {
+	products, err := uc.productRepository.Get()
+	if err != nil {
+		return 0, err
+	}
+	existing := map[string]bool{}
+	for _, product := range products {
+		existing[product.Name] = true
+	}
+
+	created := 0
+	for _, product := range data.Products {
+		if existing[product.Name] {
+			continue
+		}
+		if _, err := uc.productUsecase.CreateProduct(product); err != nil {
+			return created, fmt.Errorf("product %s: %v", product.Name, err)
+		}
+		existing[product.Name] = true
+		created++
+	}
+
+	for _, rate := range data.BenchmarkRates {
+		rates, err := uc.benchmarkRepository.GetByBenchmark(rate.Benchmark)
+		if err != nil {
+			return created, err
+		}
+		seeded := false
+		for _, r := range rates {
+			if r.EffectiveDate.Equal(rate.EffectiveDate) {
+				seeded = true
+				break
+			}
+		}
+		if seeded {
+			continue
+		}
+		if _, err := uc.benchmarkUsecase.AddRate(rate); err != nil {
+			return created, fmt.Errorf("benchmark rate %s: %v", rate.Benchmark, err)
+		}
+		created++
+	}
+
+	return created, nil
+}
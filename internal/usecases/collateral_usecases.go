@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"fmt"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"strconv"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type collateralUsecase struct {
+	collateralRepository domain.CollateralRepository
+	loanRepository       domain.LoanRepository
+	logRepository        domain.LogRepository
+}
+
+func NewCollateralUsecase(db mongoifc.Database) domain.CollateralUsecase {
+	return &collateralUsecase{
+		collateralRepository: repositories.NewCollateralRepository(db),
+		loanRepository:       repositories.NewLoanRepository(db),
+		logRepository:        repositories.NewLogRepository(db),
+	}
+}
+
+func (uc *collateralUsecase) AddCollateral(loanID string, collateral domain.Collateral) (domain.Collateral, error) {
+	if _, err := uc.loanRepository.GetByID(loanID); err != nil {
+		return domain.Collateral{}, err
+	}
+	if err := validateLienStatus(collateral.LienStatus); err != nil {
+		return domain.Collateral{}, err
+	}
+	collateral.LoanID = loanID
+	collateral.CreatedAt = time.Now()
+	if collateral.LienStatus == "" {
+		collateral.LienStatus = domain.LienStatusPending
+	}
+	if collateral.ValuationDate.IsZero() {
+		collateral.ValuationDate = collateral.CreatedAt
+	}
+	createdCollateral, err := uc.collateralRepository.Create(collateral)
+	if err != nil {
+		return domain.Collateral{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Collateral Registration",
+		Message:   fmt.Sprintf("Collateral %s appraised at %.2f was attached to loan %s", createdCollateral.ID, collateral.AppraisedValue, loanID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log collateral registration: %v\n", err)
+	}
+
+	return createdCollateral, nil
+}
+
+func (uc *collateralUsecase) UpdateCollateral(id string, collateral domain.Collateral) (domain.Collateral, error) {
+	if err := validateLienStatus(collateral.LienStatus); err != nil {
+		return domain.Collateral{}, err
+	}
+	if collateral.AppraisedValue < 0 {
+		return domain.Collateral{}, fmt.Errorf("appraised value cannot be negative")
+	}
+	collateral.LoanID = ""
+	updatedCollateral, err := uc.collateralRepository.Update(id, collateral)
+	if err != nil {
+		return domain.Collateral{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Collateral Update",
+		Message:   fmt.Sprintf("Collateral %s of loan %s was updated", id, updatedCollateral.LoanID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log collateral update: %v\n", err)
+	}
+
+	return updatedCollateral, nil
+}
+
+func (uc *collateralUsecase) DeleteCollateral(id string) error {
+	if err := uc.collateralRepository.Delete(id); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Collateral Deletion",
+		Message:   fmt.Sprintf("Collateral %s was deleted", id),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log collateral deletion: %v\n", err)
+	}
+
+	return nil
+}
+
+func (uc *collateralUsecase) GetCollateral(id string) (domain.Collateral, error) {
+	return uc.collateralRepository.GetByID(id)
+}
+
+func (uc *collateralUsecase) GetLoanToValue(loanID string) (domain.LoanToValue, error) {
+	loan, err := uc.loanRepository.GetByID(loanID)
+	if err != nil {
+		return domain.LoanToValue{}, err
+	}
+	collaterals, err := uc.collateralRepository.GetByLoanID(loanID)
+	if err != nil {
+		return domain.LoanToValue{}, err
+	}
+	return calculateLoanToValue(loan, collaterals)
+}
+
+// calculateLoanToValue compares the loan amount against the appraised value
+// of every collateral that still secures the loan. Released liens are ignored.
+func calculateLoanToValue(loan domain.Loan, collaterals []domain.Collateral) (domain.LoanToValue, error) {
+	amount, err := strconv.ParseFloat(loan.Ammount, 64)
+	if err != nil {
+		return domain.LoanToValue{}, fmt.Errorf("invalid loan amount %q", loan.Ammount)
+	}
+	ltv := domain.LoanToValue{
+		LoanID:      loan.ID,
+		LoanAmount:  amount,
+		Collaterals: collaterals,
+	}
+	for _, c := range collaterals {
+		if c.LienStatus == domain.LienStatusReleased {
+			continue
+		}
+		ltv.CollateralValue += c.AppraisedValue
+	}
+	if ltv.CollateralValue > 0 {
+		ltv.Ratio = amount / ltv.CollateralValue
+	}
+	return ltv, nil
+}
+
+func validateLienStatus(status string) error {
+	switch status {
+	case "", domain.LienStatusNone, domain.LienStatusPending, domain.LienStatusPerfected, domain.LienStatusReleased:
+		return nil
+	}
+	return fmt.Errorf("invalid lien status %s", status)
+}
@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// outboxBatchSize bounds how many emails one DeliverDue call sends, so a
+// backlog is worked off across runs instead of blocking a single one.
+const outboxBatchSize = 100
+
+// enqueueEmail writes the email to the outbox for the worker to deliver. Call
+// it with a repository bound to the transaction of the change that caused
+// the email.
+func enqueueEmail(outbox domain.EmailOutboxRepository, message domain.EmailMessage) error {
+	now := time.Now()
+	_, err := outbox.Create(domain.OutboxEmail{
+		Template:      message.Template,
+		To:            message.To,
+		Subject:       message.Subject,
+		Text:          message.Text,
+		HTML:          message.HTML,
+		Status:        domain.OutboxStatusPending,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	})
+	return err
+}
+
+type emailOutboxUsecase struct {
+	outboxRepository domain.EmailOutboxRepository
+	logRepository    domain.LogRepository
+	maxAttempts      int
+	retryDelay       time.Duration
+	maxRetryDelay    time.Duration
+}
+
+func NewEmailOutboxUsecase(db mongoifc.Database) domain.EmailOutboxUsecase {
+	uc := &emailOutboxUsecase{
+		outboxRepository: repositories.NewEmailOutboxRepository(db),
+		logRepository:    repositories.NewLogRepository(db),
+		maxAttempts:      domain.DefaultOutboxMaxAttempts,
+		retryDelay:       domain.DefaultOutboxRetryDelay,
+		maxRetryDelay:    domain.DefaultOutboxMaxRetryDelay,
+	}
+	if config, err := config.LoadConfig(); err == nil {
+		if config.Outbox.MaxAttempts > 0 {
+			uc.maxAttempts = config.Outbox.MaxAttempts
+		}
+		uc.retryDelay = parseDuration(config.Outbox.RetryDelay, uc.retryDelay)
+		uc.maxRetryDelay = parseDuration(config.Outbox.MaxRetryDelay, uc.maxRetryDelay)
+	}
+	return uc
+}
+
+// DeliverDue claims due emails one at a time, so several workers can share
+// the outbox. A failed email is retried after a delay that doubles with each
+// attempt; once it used up its attempts it is moved to the dead letters.
+func (uc *emailOutboxUsecase) DeliverDue(mailer domain.Mailer, now time.Time) (int, error) {
+	sent := 0
+	for i := 0; i < outboxBatchSize; i++ {
+		email, err := uc.outboxRepository.ClaimDue(now, time.Now().Add(domain.OutboxClaimTimeout))
+		if errors.Is(err, repositories.ErrNoOutboxEmailDue) {
+			break
+		}
+		if err != nil {
+			return sent, err
+		}
+
+		sendErr := mailer.Send(email.Message())
+		switch {
+		case sendErr == nil:
+			err = uc.outboxRepository.MarkSent(email.ID, time.Now())
+			sent++
+		case email.Attempts >= uc.maxAttempts:
+			err = uc.outboxRepository.MarkDead(email.ID, sendErr.Error())
+			uc.log("Email Delivery", fmt.Sprintf("Email %s (%s) to %v moved to dead letters after %d attempts: %v", email.ID, email.Template, email.To, email.Attempts, sendErr))
+		default:
+			err = uc.outboxRepository.Reschedule(email.ID, time.Now().Add(uc.backoff(email.Attempts)), sendErr.Error())
+		}
+		// The email may have been deleted with its recipient's account while
+		// it was being sent.
+		if err != nil && !errors.Is(err, repositories.ErrOutboxEmailNotFound) {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// backoff is the delay before the attempt following the given one.
+func (uc *emailOutboxUsecase) backoff(attempts int) time.Duration {
+	delay := uc.retryDelay
+	for i := 1; i < attempts && delay < uc.maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > uc.maxRetryDelay {
+		delay = uc.maxRetryDelay
+	}
+	return delay
+}
+
+func (uc *emailOutboxUsecase) GetDeadLetters() ([]domain.OutboxEmail, error) {
+	return uc.outboxRepository.GetByStatus(domain.OutboxStatusDead)
+}
+
+// RetryDeadLetter queues a dead email for delivery again with a fresh set of
+// attempts.
+func (uc *emailOutboxUsecase) RetryDeadLetter(actorID, id string) (domain.OutboxEmail, error) {
+	email, err := uc.outboxRepository.Requeue(id, time.Now())
+	if err != nil {
+		return domain.OutboxEmail{}, err
+	}
+	uc.log("Email Delivery", fmt.Sprintf("Admin %s requeued dead email %s (%s) to %v", actorID, email.ID, email.Template, email.To))
+	return email, nil
+}
+
+func (uc *emailOutboxUsecase) log(category, message string) {
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  category,
+		Message:   message,
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log email delivery event: %v\n", err)
+	}
+}
@@ -0,0 +1,229 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/pkg/infrastructures"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// memoryOutbox is an EmailOutboxRepository over a slice, claiming emails in
+// insertion order.
+type memoryOutbox struct {
+	emails []domain.OutboxEmail
+}
+
+func (r *memoryOutbox) WithContext(ctx context.Context) domain.EmailOutboxRepository { return r }
+
+func (r *memoryOutbox) Create(email domain.OutboxEmail) (domain.OutboxEmail, error) {
+	email.ID = strconv.Itoa(len(r.emails) + 1)
+	r.emails = append(r.emails, email)
+	return email, nil
+}
+
+func (r *memoryOutbox) find(id string) *domain.OutboxEmail {
+	for i := range r.emails {
+		if r.emails[i].ID == id {
+			return &r.emails[i]
+		}
+	}
+	return nil
+}
+
+func (r *memoryOutbox) GetByID(id string) (domain.OutboxEmail, error) {
+	if email := r.find(id); email != nil {
+		return *email, nil
+	}
+	return domain.OutboxEmail{}, repositories.ErrOutboxEmailNotFound
+}
+
+func (r *memoryOutbox) GetByStatus(status string) ([]domain.OutboxEmail, error) {
+	emails := []domain.OutboxEmail{}
+	for _, email := range r.emails {
+		if email.Status == status {
+			emails = append(emails, email)
+		}
+	}
+	return emails, nil
+}
+
+func (r *memoryOutbox) ClaimDue(now, claimedUntil time.Time) (domain.OutboxEmail, error) {
+	for i := range r.emails {
+		email := &r.emails[i]
+		due := email.Status == domain.OutboxStatusPending && !email.NextAttemptAt.After(now)
+		lapsed := email.Status == domain.OutboxStatusSending && email.ClaimedUntil.Before(now)
+		if due || lapsed {
+			email.Status = domain.OutboxStatusSending
+			email.ClaimedUntil = claimedUntil
+			email.Attempts++
+			return *email, nil
+		}
+	}
+	return domain.OutboxEmail{}, repositories.ErrNoOutboxEmailDue
+}
+
+func (r *memoryOutbox) MarkSent(id string, at time.Time) error {
+	email := r.find(id)
+	email.Status = domain.OutboxStatusSent
+	email.SentAt = at
+	return nil
+}
+
+func (r *memoryOutbox) Reschedule(id string, nextAttemptAt time.Time, lastError string) error {
+	email := r.find(id)
+	email.Status = domain.OutboxStatusPending
+	email.NextAttemptAt = nextAttemptAt
+	email.LastError = lastError
+	return nil
+}
+
+func (r *memoryOutbox) MarkDead(id, lastError string) error {
+	email := r.find(id)
+	email.Status = domain.OutboxStatusDead
+	email.LastError = lastError
+	return nil
+}
+
+func (r *memoryOutbox) Requeue(id string, now time.Time) (domain.OutboxEmail, error) {
+	email := r.find(id)
+	email.Status = domain.OutboxStatusPending
+	email.Attempts = 0
+	email.NextAttemptAt = now
+	return *email, nil
+}
+
+func (r *memoryOutbox) DeleteByRecipient(address string) (int64, error) {
+	kept := r.emails[:0]
+	for _, email := range r.emails {
+		if email.To[0] != address {
+			kept = append(kept, email)
+		}
+	}
+	deleted := int64(len(r.emails) - len(kept))
+	r.emails = kept
+	return deleted, nil
+}
+
+type memoryLogs struct {
+	logs []domain.SystemLog
+}
+
+func (r *memoryLogs) Create(log domain.SystemLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *memoryLogs) GetAll() ([]domain.SystemLog, error) { return r.logs, nil }
+
+type failingMailer struct{}
+
+func (failingMailer) Send(domain.EmailMessage) error { return errors.New("connection refused") }
+
+func newTestOutboxUsecase() (*emailOutboxUsecase, *memoryOutbox, *memoryLogs) {
+	outbox := &memoryOutbox{}
+	logs := &memoryLogs{}
+	return &emailOutboxUsecase{
+		outboxRepository: outbox,
+		logRepository:    logs,
+		maxAttempts:      3,
+		retryDelay:       30 * time.Second,
+		maxRetryDelay:    2 * time.Minute,
+	}, outbox, logs
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	uc, _, _ := newTestOutboxUsecase()
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute, 2 * time.Minute}
+	for i, delay := range want {
+		if got := uc.backoff(i + 1); got != delay {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, delay)
+		}
+	}
+	if got := uc.backoff(1000); got != 2*time.Minute {
+		t.Errorf("backoff(1000) = %v, want the maximum", got)
+	}
+}
+
+func TestDeliverDueSendsQueuedEmails(t *testing.T) {
+	uc, outbox, _ := newTestOutboxUsecase()
+	for _, to := range []string{"alice@example.com", "bob@example.com"} {
+		message := domain.EmailMessage{Template: infrastructures.EmailVerification, To: []string{to}, Subject: "Verify", Text: "text", HTML: "<p>html</p>"}
+		if err := enqueueEmail(outbox, message); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	mailer := &infrastructures.MemoryMailer{}
+	sent, err := uc.DeliverDue(mailer, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("DeliverDue() = %d, want 2", sent)
+	}
+	messages := mailer.Messages()
+	if len(messages) != 2 || messages[0].To[0] != "alice@example.com" || messages[1].HTML != "<p>html</p>" {
+		t.Errorf("mailer received %+v", messages)
+	}
+	for _, email := range outbox.emails {
+		if email.Status != domain.OutboxStatusSent || email.Attempts != 1 {
+			t.Errorf("email %s is %s after %d attempts, want sent after 1", email.ID, email.Status, email.Attempts)
+		}
+	}
+
+	if sent, _ := uc.DeliverDue(mailer, time.Now()); sent != 0 || len(mailer.Messages()) != 2 {
+		t.Errorf("sent emails were delivered again")
+	}
+}
+
+func TestDeliverDueRetriesThenDeadLetters(t *testing.T) {
+	uc, outbox, logs := newTestOutboxUsecase()
+	if err := enqueueEmail(outbox, domain.EmailMessage{To: []string{"alice@example.com"}, Subject: "Verify"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	for attempt := 1; attempt < uc.maxAttempts; attempt++ {
+		if sent, err := uc.DeliverDue(failingMailer{}, now); sent != 0 || err != nil {
+			t.Fatalf("attempt %d: DeliverDue() = %d, %v", attempt, sent, err)
+		}
+		email := outbox.emails[0]
+		if email.Status != domain.OutboxStatusPending || email.LastError != "connection refused" {
+			t.Fatalf("attempt %d: email is %s with error %q, want pending", attempt, email.Status, email.LastError)
+		}
+		if !email.NextAttemptAt.After(now) {
+			t.Fatalf("attempt %d: retry is not delayed", attempt)
+		}
+		// Nothing is due until the backoff has passed.
+		if _, err := uc.outboxRepository.ClaimDue(now, now); !errors.Is(err, repositories.ErrNoOutboxEmailDue) {
+			t.Fatalf("attempt %d: email was due again before its retry time", attempt)
+		}
+		now = email.NextAttemptAt
+	}
+
+	if _, err := uc.DeliverDue(failingMailer{}, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if email := outbox.emails[0]; email.Status != domain.OutboxStatusDead || email.Attempts != uc.maxAttempts {
+		t.Fatalf("email is %s after %d attempts, want dead after %d", email.Status, email.Attempts, uc.maxAttempts)
+	}
+	if len(logs.logs) != 1 || logs.logs[0].Category != "Email Delivery" {
+		t.Errorf("dead letter was not logged: %+v", logs.logs)
+	}
+
+	dead, _ := uc.GetDeadLetters()
+	if len(dead) != 1 {
+		t.Fatalf("GetDeadLetters() = %+v", dead)
+	}
+	if _, err := uc.RetryDeadLetter("admin", dead[0].ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mailer := &infrastructures.MemoryMailer{}
+	if sent, err := uc.DeliverDue(mailer, time.Now()); sent != 1 || err != nil {
+		t.Errorf("requeued email: DeliverDue() = %d, %v, want 1", sent, err)
+	}
+}
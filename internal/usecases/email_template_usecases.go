@@ -0,0 +1,40 @@
+package usecases
+
+import (
+	"loan-management/internal/domain"
+	"loan-management/pkg/infrastructures"
+	"sort"
+)
+
+type emailTemplateUsecase struct{}
+
+func NewEmailTemplateUsecase() domain.EmailTemplateUsecase {
+	return &emailTemplateUsecase{}
+}
+
+func (uc *emailTemplateUsecase) GetEmailTemplates() ([]domain.EmailTemplate, error) {
+	locales, err := infrastructures.EmailTemplateLocales()
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]domain.EmailTemplate, 0, len(locales))
+	for name, available := range locales {
+		templates = append(templates, domain.EmailTemplate{Name: name, Locales: available})
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+func (uc *emailTemplateUsecase) PreviewEmailTemplate(name, locale string) (domain.EmailPreview, error) {
+	email, err := infrastructures.PreviewEmail(name, locale)
+	if err != nil {
+		return domain.EmailPreview{}, err
+	}
+	return domain.EmailPreview{
+		Name:    name,
+		Locale:  email.Locale,
+		Subject: email.Subject,
+		HTML:    email.HTML,
+		Text:    email.Text,
+	}, nil
+}
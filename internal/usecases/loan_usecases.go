@@ -1,29 +1,74 @@
 package usecases
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"loan-management/config"
 	"loan-management/internal/domain"
 	"loan-management/internal/repositories"
+	"strconv"
 	"time"
 
 	"github.com/sv-tools/mongoifc"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrTermTooLong is returned for loans and products whose term exceeds the
+// configured maximum.
+var ErrTermTooLong = errors.New("term is too long")
+
+// ErrOfferClosed is returned when an offer was accepted, declined or expired
+// after it was read.
+var ErrOfferClosed = errors.New("offer is no longer open")
+
 type loanUsecase struct {
-	loanRepository domain.LoanRepository
-	logRepository  domain.LogRepository
+	loanRepository       domain.LoanRepository
+	logRepository        domain.LogRepository
+	collateralRepository domain.CollateralRepository
+	productRepository    domain.LoanProductRepository
+	benchmarkRepository  domain.BenchmarkRateRepository
+	transactor           domain.Transactor
+	notifier             *notifier
 }
 
 func NewLoanUsecase(db mongoifc.Database) domain.LoanUsecase {
 	loanRepo := repositories.NewLoanRepository(db)
 	logRepo := repositories.NewLogRepository(db)
+	collateralRepo := repositories.NewCollateralRepository(db)
+	productRepo := repositories.NewLoanProductRepository(db)
+	benchmarkRepo := repositories.NewBenchmarkRateRepository(db)
 	return &loanUsecase{
-		loanRepository: loanRepo,
-		logRepository:  logRepo,
+		loanRepository:       loanRepo,
+		logRepository:        logRepo,
+		collateralRepository: collateralRepo,
+		productRepository:    productRepo,
+		benchmarkRepository:  benchmarkRepo,
+		transactor:           repositories.NewTransactor(db),
+		notifier:             newNotifier(db),
 	}
 }
 
+// inTransaction runs fn with a copy of the usecase whose loan repository and
+// notifier share one transaction, so the borrower is notified exactly when
+// the loan change is saved.
+func (uc *loanUsecase) inTransaction(fn func(tx *loanUsecase) error) error {
+	return uc.transactor.WithTransaction(func(ctx context.Context) error {
+		tx := *uc
+		tx.loanRepository = uc.loanRepository.WithContext(ctx)
+		tx.notifier = uc.notifier.withContext(ctx)
+		return fn(&tx)
+	})
+}
+
+// notifyLoan notifies the borrower of an event of their loan. Events happen
+// once per loan, so the type and loan identify them.
+func (uc *loanUsecase) notifyLoan(loan domain.Loan, kind domain.NotificationType, data map[string]interface{}) error {
+	data["LoanID"] = loan.ID
+	_, err := uc.notifier.notify(loan.UserID, kind, loan.ID, string(kind)+":"+loan.ID, data)
+	return err
+}
+
 func (uc *loanUsecase) DeleteLoan(id string) error {
 	err := uc.loanRepository.Delete(id)
 	if err != nil {
@@ -43,19 +88,47 @@ func (uc *loanUsecase) DeleteLoan(id string) error {
 	return nil
 }
 
-// ApproveRejectLoan updates the status of a loan
+// ApproveRejectLoan updates the status of a loan. Approving a loan prices it
+// and turns it into an offer the borrower has to accept.
 func (uc *loanUsecase) ApproveRejectLoan(id string, status string) (domain.Loan, error) {
 	loan, err := uc.loanRepository.GetByID(id)
 	if err != nil {
 		return domain.Loan{}, err
 	}
 
-	if loan.Status != "pending" {
+	if loan.Status != domain.LoanStatusPending {
 		return domain.Loan{}, fmt.Errorf("loan status cannot be updated from %s", loan.Status)
 	}
 
 	loan.Status = status
-	updatedLoan, err := uc.loanRepository.Update(id, loan)
+	if status == "approved" {
+		if err := uc.checkLoanToValue(loan); err != nil {
+			return domain.Loan{}, err
+		}
+		if err := uc.makeOffer(&loan, time.Now()); err != nil {
+			return domain.Loan{}, err
+		}
+	}
+	var updatedLoan domain.Loan
+	err = uc.inTransaction(func(tx *loanUsecase) error {
+		updatedLoan, err = tx.loanRepository.Update(id, loan)
+		if err != nil {
+			return err
+		}
+		if loan.Status == domain.LoanStatusRejected {
+			return tx.notifyLoan(loan, domain.NotificationLoanRejected, map[string]interface{}{})
+		}
+		if loan.Offer == nil {
+			return nil
+		}
+		return tx.notifyLoan(loan, domain.NotificationLoanApproved, map[string]interface{}{
+			"Amount":         loan.Offer.Amount,
+			"Rate":           loan.Offer.InterestRate * 100,
+			"TermMonths":     loan.Offer.TermMonths,
+			"MonthlyPayment": loan.Offer.MonthlyPayment,
+			"ExpiresAt":      loan.Offer.ExpiresAt,
+		})
+	})
 	if err != nil {
 		return domain.Loan{}, err
 	}
@@ -73,14 +146,356 @@ func (uc *loanUsecase) ApproveRejectLoan(id string, status string) (domain.Loan,
 	return updatedLoan, nil
 }
 
+// WriteOffLoan writes off an accepted loan that is deemed uncollectable.
+// Interest stops accruing once the loan leaves the accepted status.
+func (uc *loanUsecase) WriteOffLoan(id, reason string) (domain.Loan, error) {
+	loan, err := uc.loanRepository.GetByID(id)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if loan.Status != domain.LoanStatusAccepted {
+		return domain.Loan{}, fmt.Errorf("loan cannot be written off from %s", loan.Status)
+	}
+	updatedLoan, err := uc.loanRepository.Update(id, domain.Loan{
+		Status:       domain.LoanStatusWrittenOff,
+		WrittenOffAt: time.Now(),
+	})
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Loan Write-Off",
+		Message:   fmt.Sprintf("Loan %s was written off: %s", id, reason),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log loan write-off: %v\n", err)
+	}
+
+	return updatedLoan, nil
+}
+
+// makeOffer prices an approved loan and attaches the offer the borrower has
+// to accept before it expires.
+func (uc *loanUsecase) makeOffer(loan *domain.Loan, approvedAt time.Time) error {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	validity, err := time.ParseDuration(config.Loan.OfferValidity)
+	if err != nil || validity <= 0 {
+		validity = domain.DefaultOfferValidity
+	}
+	principal, err := strconv.ParseFloat(loan.Ammount, 64)
+	if err != nil {
+		return fmt.Errorf("invalid loan amount %q", loan.Ammount)
+	}
+	loan.Fees = roundCents(principal * config.Loan.OriginationFeeRate)
+	if loan.ProductID != "" {
+		product, err := uc.productRepository.GetByID(loan.ProductID)
+		if err != nil {
+			return err
+		}
+		rate, err := productRate(product, uc.benchmarkRepository, approvedAt)
+		if err != nil {
+			return err
+		}
+		loan.InterestRate = rate
+		loan.Fees = roundCents(product.OriginationFee + principal*product.OriginationFeeRate)
+	}
+	if err := uc.scheduleLoan(loan, approvedAt); err != nil {
+		return err
+	}
+	cost, err := calculateCostOfCredit(principal, loan.Fees, loan.Schedule)
+	if err != nil {
+		return err
+	}
+
+	offer := domain.Offer{
+		Amount:       principal,
+		InterestRate: loan.InterestRate,
+		TermMonths:   loan.TermMonths,
+		CostOfCredit: cost,
+		ExpiresAt:    approvedAt.Add(validity),
+		CreatedAt:    approvedAt,
+	}
+	if len(loan.Schedule) > 0 {
+		offer.MonthlyPayment = loan.Schedule[0].Payment
+	}
+
+	loan.Status = domain.LoanStatusOffered
+	loan.ApprovedAt = approvedAt
+	loan.Offer = &offer
+	return nil
+}
+
+// scheduleLoan builds the amortization schedule of a loan at its current rate
+// starting from start and, for variable-rate products, its first reset date.
+func (uc *loanUsecase) scheduleLoan(loan *domain.Loan, start time.Time) error {
+	principal, err := strconv.ParseFloat(loan.Ammount, 64)
+	if err != nil {
+		return fmt.Errorf("invalid loan amount %q", loan.Ammount)
+	}
+	// Applications stored before terms were capped may still be too long.
+	if max := maxTermMonths(); loan.TermMonths <= 0 || loan.TermMonths > max {
+		return fmt.Errorf("%w, the term must be between 1 and %d months", ErrTermTooLong, max)
+	}
+	if loan.ProductID != "" {
+		product, err := uc.productRepository.GetByID(loan.ProductID)
+		if err != nil {
+			return err
+		}
+		if product.RateType == domain.RateTypeVariable {
+			loan.NextRateReset = addMonths(truncateToDay(start), product.ResetFrequencyMonths)
+		}
+	}
+	loan.Schedule = buildAmortizationSchedule(principal, loan.InterestRate, loan.TermMonths, start)
+	return nil
+}
+
+// AcceptOffer activates an offered loan at the offered terms. The schedule is
+// rebuilt from the acceptance date, from which interest starts to accrue.
+func (uc *loanUsecase) AcceptOffer(id string, userID string) (domain.Loan, error) {
+	loan, err := uc.openOffer(id, userID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	now := time.Now()
+	loan.InterestRate = loan.Offer.InterestRate
+	if err := uc.scheduleLoan(&loan, now); err != nil {
+		return domain.Loan{}, err
+	}
+	loan.Status = domain.LoanStatusAccepted
+	loan.AcceptedAt = now
+	loan.Offer.RespondedAt = now
+	// Accepting the offer releases the funds, so the borrower is told the
+	// loan was disbursed and when the first payment is due.
+	var updatedLoan domain.Loan
+	err = uc.inTransaction(func(tx *loanUsecase) error {
+		if err := tx.closeOffer(id, domain.LoanStatusAccepted); err != nil {
+			return err
+		}
+		updatedLoan, err = tx.loanRepository.Update(id, loan)
+		if err != nil {
+			return err
+		}
+		data := map[string]interface{}{"Amount": loan.Ammount}
+		if len(loan.Schedule) > 0 {
+			data["Payment"] = loan.Schedule[0].Payment
+			data["FirstDueDate"] = loan.Schedule[0].DueDate
+		}
+		return tx.notifyLoan(loan, domain.NotificationLoanDisbursed, data)
+	})
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Loan Offer Response",
+		Message:   fmt.Sprintf("User %s accepted the offer for loan %s", userID, id),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log loan offer acceptance: %v\n", err)
+	}
+
+	return updatedLoan, nil
+}
+
+func (uc *loanUsecase) DeclineOffer(id string, userID string) (domain.Loan, error) {
+	loan, err := uc.openOffer(id, userID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	loan.Status = domain.LoanStatusDeclined
+	loan.Offer.RespondedAt = time.Now()
+	var updatedLoan domain.Loan
+	err = uc.inTransaction(func(tx *loanUsecase) error {
+		if err := tx.closeOffer(id, domain.LoanStatusDeclined); err != nil {
+			return err
+		}
+		updatedLoan, err = tx.loanRepository.Update(id, domain.Loan{Status: loan.Status, Offer: loan.Offer})
+		return err
+	})
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Loan Offer Response",
+		Message:   fmt.Sprintf("User %s declined the offer for loan %s", userID, id),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log loan offer decline: %v\n", err)
+	}
+
+	return updatedLoan, nil
+}
+
+// openOffer returns the borrower's loan if it holds an offer that can still be
+// answered. Offers found past their expiry are expired on the spot.
+func (uc *loanUsecase) openOffer(id string, userID string) (domain.Loan, error) {
+	loan, err := uc.loanRepository.GetByID(id)
+	if err != nil || loan.UserID != userID {
+		return domain.Loan{}, fmt.Errorf("loan not found")
+	}
+	if loan.Status != domain.LoanStatusOffered || loan.Offer == nil {
+		return domain.Loan{}, fmt.Errorf("loan has no open offer")
+	}
+	if time.Now().After(loan.Offer.ExpiresAt) {
+		if err := uc.expireOffer(loan); err != nil {
+			return domain.Loan{}, err
+		}
+		return domain.Loan{}, fmt.Errorf("offer has expired")
+	}
+	return loan, nil
+}
+
+// ExpireOffers expires every offer whose expiry has passed and returns how
+// many were expired.
+func (uc *loanUsecase) ExpireOffers(asOf time.Time) (int, error) {
+	loans, err := uc.loanRepository.Get(map[string]string{"status": domain.LoanStatusOffered})
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, loan := range loans {
+		if loan.Offer == nil || asOf.Before(loan.Offer.ExpiresAt) {
+			continue
+		}
+		if err := uc.expireOffer(loan); err != nil {
+			// The borrower answered the offer since it was read.
+			if errors.Is(err, ErrOfferClosed) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func (uc *loanUsecase) expireOffer(loan domain.Loan) error {
+	if err := uc.closeOffer(loan.ID, domain.LoanStatusExpired); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Loan Offer Expiry",
+		Message:   fmt.Sprintf("The offer for loan %s expired on %s", loan.ID, loan.Offer.ExpiresAt.Format(time.RFC3339)),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log loan offer expiry: %v\n", err)
+	}
+	return nil
+}
+
+// closeOffer moves a loan out of the offered status. It fails with
+// ErrOfferClosed if the offer was accepted, declined or expired meanwhile.
+func (uc *loanUsecase) closeOffer(id string, status string) error {
+	err := uc.loanRepository.TransitionStatus(id, domain.LoanStatusOffered, status)
+	if errors.Is(err, repositories.ErrLoanStatusChanged) {
+		return ErrOfferClosed
+	}
+	return err
+}
+
+// checkLoanToValue rejects the approval of a secured loan whose amount exceeds
+// the configured share of its collateral value. Unsecured loans are not checked.
+func (uc *loanUsecase) checkLoanToValue(loan domain.Loan) error {
+	collaterals, err := uc.collateralRepository.GetByLoanID(loan.ID)
+	if err != nil {
+		return err
+	}
+	if len(collaterals) == 0 {
+		return nil
+	}
+	config, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	maxLTV := config.Loan.MaxLTV
+	if maxLTV == 0 {
+		maxLTV = domain.DefaultMaxLTV
+	}
+	ltv, err := calculateLoanToValue(loan, collaterals)
+	if err != nil {
+		return err
+	}
+	if ltv.CollateralValue == 0 {
+		return fmt.Errorf("loan has no active collateral")
+	}
+	if ltv.Ratio > maxLTV {
+		return fmt.Errorf("loan-to-value ratio %.2f exceeds the limit of %.2f", ltv.Ratio, maxLTV)
+	}
+	return nil
+}
+
 // CreateLoan creates a new loan application
-func (uc *loanUsecase) CreateLoan(userID string, amount string) (domain.Loan, error) {
+func (uc *loanUsecase) CreateLoan(userID string, application domain.Loan) (domain.Loan, error) {
+	amount := application.Ammount
+	if value, err := strconv.ParseFloat(amount, 64); err != nil || value <= 0 {
+		return domain.Loan{}, fmt.Errorf("amount must be a positive number")
+	}
+	config, err := config.LoadConfig()
+	if err != nil {
+		return domain.Loan{}, err
+	}
 	loan := domain.Loan{
-		UserID:  userID,
-		Ammount: amount,
-		Status:  "pending",
+		UserID:             userID,
+		Ammount:            amount,
+		Status:             domain.LoanStatusPending,
+		CreatedAt:          time.Now(),
+		ProductID:          application.ProductID,
+		TermMonths:         application.TermMonths,
+		InterestRate:       config.Loan.InterestRate,
+		DayCountConvention: config.Loan.DayCountConvention,
+	}
+	if loan.ProductID != "" {
+		product, err := uc.productRepository.GetByID(loan.ProductID)
+		if err != nil {
+			return domain.Loan{}, err
+		}
+		rate, err := productRate(product, uc.benchmarkRepository, loan.CreatedAt)
+		if err != nil {
+			return domain.Loan{}, err
+		}
+		loan.InterestRate = rate
+		loan.DayCountConvention = product.DayCountConvention
+		if loan.TermMonths == 0 {
+			loan.TermMonths = product.TermMonths
+		}
+	}
+	if loan.TermMonths == 0 {
+		loan.TermMonths = config.Loan.TermMonths
+	}
+	if loan.TermMonths <= 0 {
+		loan.TermMonths = domain.DefaultTermMonths
+	}
+	if max := maxTermMonths(); loan.TermMonths > max {
+		return domain.Loan{}, fmt.Errorf("%w, the longest term is %d months", ErrTermTooLong, max)
+	}
+	if loan.DayCountConvention == "" {
+		loan.DayCountConvention = domain.DayCountActual365
 	}
-	createdLoan, err := uc.loanRepository.Create(loan)
+	if _, err := dayCountFraction(loan.DayCountConvention, loan.CreatedAt, loan.CreatedAt); err != nil {
+		return domain.Loan{}, err
+	}
+	var createdLoan domain.Loan
+	err = uc.inTransaction(func(tx *loanUsecase) error {
+		createdLoan, err = tx.loanRepository.Create(loan)
+		if err != nil {
+			return err
+		}
+		return tx.notifyLoan(createdLoan, domain.NotificationLoanSubmitted, map[string]interface{}{"Amount": amount})
+	})
 	if err != nil {
 		return domain.Loan{}, err
 	}
@@ -99,6 +514,33 @@ func (uc *loanUsecase) CreateLoan(userID string, amount string) (domain.Loan, er
 	return createdLoan, nil
 }
 
+// RecordPayment records that the installment of an active loan was paid, so
+// the borrower is no longer reminded of it.
+func (uc *loanUsecase) RecordPayment(id string, number int) (domain.Loan, error) {
+	loan, err := uc.loanRepository.GetByID(id)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if loan.Status != domain.LoanStatusAccepted {
+		return domain.Loan{}, fmt.Errorf("payments cannot be recorded for a %s loan", loan.Status)
+	}
+	if err := uc.loanRepository.MarkInstallmentPaid(id, number, time.Now()); err != nil {
+		return domain.Loan{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Loan Payment",
+		Message:   fmt.Sprintf("Payment of installment %d of loan %s was recorded", number, id),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log loan payment: %v\n", err)
+	}
+
+	return uc.loanRepository.GetByID(id)
+}
+
 // ViewAllLoans retrieves all loans based on filters
 func (uc *loanUsecase) ViewAllLoans(filter map[string]string) ([]domain.Loan, error) {
 	loans, err := uc.loanRepository.Get(filter)
@@ -109,7 +551,31 @@ func (uc *loanUsecase) ViewAllLoans(filter map[string]string) ([]domain.Loan, er
 	return loans, nil
 }
 
-// ViewLoanStatus retrieves a loan's status by ID
+// ViewLoanStatus retrieves a loan's status by ID along with its cost of
+// credit once it has been priced
 func (uc *loanUsecase) ViewLoanStatus(id string) (domain.Loan, error) {
-	return uc.loanRepository.GetByID(id)
+	loan, err := uc.loanRepository.GetByID(id)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if len(loan.Schedule) == 0 {
+		return loan, nil
+	}
+	principal, err := strconv.ParseFloat(loan.Ammount, 64)
+	if err != nil {
+		return loan, nil
+	}
+	if cost, err := calculateCostOfCredit(principal, loan.Fees, loan.Schedule); err == nil {
+		loan.CostOfCredit = &cost
+	}
+	return loan, nil
+}
+
+// maxTermMonths is the longest term a loan or product may have, since a
+// schedule is built with one installment per month.
+func maxTermMonths() int {
+	if config, err := config.LoadConfig(); err == nil && config.Loan.MaxTermMonths > 0 {
+		return config.Loan.MaxTermMonths
+	}
+	return domain.DefaultMaxTermMonths
 }
@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/pkg/infrastructures"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrLoginThrottled is returned while an account or client IP has to wait
+// before trying to log in again.
+var ErrLoginThrottled = errors.New("too many failed login attempts")
+
+type throttlePolicy struct {
+	maxAccountAttempts int
+	maxIPAttempts      int
+	window             time.Duration
+	lockout            time.Duration
+	delay              time.Duration
+}
+
+func loadThrottlePolicy() throttlePolicy {
+	policy := throttlePolicy{
+		maxAccountAttempts: domain.DefaultMaxAccountAttempts,
+		maxIPAttempts:      domain.DefaultMaxIPAttempts,
+		window:             domain.DefaultAttemptWindow,
+		lockout:            domain.DefaultLockoutDuration,
+		delay:              domain.DefaultLoginDelay,
+	}
+	config, err := config.LoadConfig()
+	if err != nil {
+		return policy
+	}
+	if config.Lockout.MaxAccountAttempts > 0 {
+		policy.maxAccountAttempts = config.Lockout.MaxAccountAttempts
+	}
+	if config.Lockout.MaxIPAttempts > 0 {
+		policy.maxIPAttempts = config.Lockout.MaxIPAttempts
+	}
+	policy.window = parseDuration(config.Lockout.Window, policy.window)
+	policy.lockout = parseDuration(config.Lockout.Duration, policy.lockout)
+	policy.delay = parseDuration(config.Lockout.Delay, policy.delay)
+	return policy
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// loginDelay is how long to wait after the given number of consecutive
+// failures. It doubles with every failure up to MaxLoginDelay.
+func loginDelay(base time.Duration, failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= domain.MaxLoginDelay {
+			return domain.MaxLoginDelay
+		}
+	}
+	return delay
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + email
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginThrottle rejects an attempt while the account or IP is locked out
+// or still waiting out its progressive delay.
+func (uc *userUsecase) checkLoginThrottle(email, ip string) error {
+	policy := loadThrottlePolicy()
+	now := time.Now()
+	for _, key := range []string{ipThrottleKey(ip), accountThrottleKey(email)} {
+		throttle, err := uc.throttleRepository.Get(key)
+		if err != nil {
+			return err
+		}
+		if now.Before(throttle.LockedUntil) {
+			return fmt.Errorf("%w, login is locked until %s", ErrLoginThrottled, throttle.LockedUntil.Format(time.RFC3339))
+		}
+		if throttle.Failures == 0 || throttle.LastFailureAt.Before(now.Add(-policy.window)) {
+			continue
+		}
+		retryAt := throttle.LastFailureAt.Add(loginDelay(policy.delay, throttle.Failures))
+		if now.Before(retryAt) {
+			return fmt.Errorf("%w, try again in %s", ErrLoginThrottled, retryAt.Sub(now).Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt against the account and the IP
+// and locks whichever exceeded its limit. A locked account is emailed a link
+// to unlock it early.
+func (uc *userUsecase) recordLoginFailure(email, ip string) {
+	policy := loadThrottlePolicy()
+	now := time.Now()
+
+	account, err := uc.throttleRepository.RecordFailure(accountThrottleKey(email), now, policy.window)
+	if err != nil {
+		fmt.Printf("Failed to record login failure: %v\n", err)
+	} else if account.Failures >= policy.maxAccountAttempts {
+		uc.lockLogin(account.Key, now.Add(policy.lockout), fmt.Sprintf("Account %s locked after %d failed login attempts, last from %s", email, account.Failures, ip))
+		if user, err := uc.userRepository.GetByEmail(email); err == nil {
+			err := uc.inTransaction(func(tx *userUsecase) error {
+				token, err := tx.issueToken(user.ID, user.Email, domain.TokenAccountUnlock, 24*time.Hour)
+				if err != nil {
+					return err
+				}
+				message, err := infrastructures.AccountUnlockEmail(user.Email, user.Language, token)
+				if err != nil {
+					return err
+				}
+				return enqueueEmail(tx.outboxRepository, message)
+			})
+			if err != nil {
+				fmt.Printf("Failed to queue account unlock email: %v\n", err)
+			}
+		}
+	}
+
+	address, err := uc.throttleRepository.RecordFailure(ipThrottleKey(ip), now, policy.window)
+	if err != nil {
+		fmt.Printf("Failed to record login failure: %v\n", err)
+	} else if address.Failures >= policy.maxIPAttempts {
+		uc.lockLogin(address.Key, now.Add(policy.lockout), fmt.Sprintf("IP %s locked after %d failed login attempts", ip, address.Failures))
+	}
+}
+
+func (uc *userUsecase) lockLogin(key string, until time.Time, message string) {
+	if err := uc.throttleRepository.Lock(key, until); err != nil {
+		fmt.Printf("Failed to lock login: %v\n", err)
+		return
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Account Lockout",
+		Message:   message,
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log account lockout: %v\n", err)
+	}
+}
@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"loan-management/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		base     time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{time.Second, -1, 0},
+		{time.Second, 0, 0},
+		{time.Second, 1, time.Second},
+		{time.Second, 2, 2 * time.Second},
+		{time.Second, 3, 4 * time.Second},
+		{time.Second, 5, 16 * time.Second},
+		{time.Second, 6, domain.MaxLoginDelay},
+		{time.Second, 1000, domain.MaxLoginDelay},
+		{500 * time.Millisecond, 4, 4 * time.Second},
+		{time.Minute, 1, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := loginDelay(tt.base, tt.failures); got != tt.want {
+			t.Errorf("loginDelay(%v, %d) = %v, want %v", tt.base, tt.failures, got, tt.want)
+		}
+	}
+}
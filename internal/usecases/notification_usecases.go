@@ -0,0 +1,172 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type notificationUsecase struct {
+	notificationRepository domain.NotificationRepository
+	userRepository         domain.UserRepository
+	loanRepository         domain.LoanRepository
+	logRepository          domain.LogRepository
+	transactor             domain.Transactor
+	notifier               *notifier
+}
+
+func NewNotificationUsecase(db mongoifc.Database) domain.NotificationUsecase {
+	return &notificationUsecase{
+		notificationRepository: repositories.NewNotificationRepository(db),
+		userRepository:         repositories.NewUserRepository(db),
+		loanRepository:         repositories.NewLoanRepository(db),
+		logRepository:          repositories.NewLogRepository(db),
+		transactor:             repositories.NewTransactor(db),
+		notifier:               newNotifier(db),
+	}
+}
+
+func (uc *notificationUsecase) GetNotifications(userID string) ([]domain.Notification, error) {
+	return uc.notificationRepository.GetByUser(userID)
+}
+
+func (uc *notificationUsecase) MarkRead(userID, id string) error {
+	return uc.notificationRepository.MarkRead(userID, id, time.Now())
+}
+
+func (uc *notificationUsecase) GetPreferences(userID string) ([]domain.NotificationPreference, error) {
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return notificationPreferences(user), nil
+}
+
+func (uc *notificationUsecase) UpdatePreferences(userID string, enabled map[domain.NotificationType]bool) ([]domain.NotificationPreference, error) {
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for kind, on := range enabled {
+		if !validNotificationType(kind) {
+			return nil, fmt.Errorf("unknown notification type %s", kind)
+		}
+		if !on && domain.MandatoryNotifications[kind] {
+			return nil, fmt.Errorf("%s notifications cannot be disabled", kind)
+		}
+	}
+	optOuts := []domain.NotificationType{}
+	for _, kind := range domain.NotificationTypes {
+		on, changed := enabled[kind]
+		if !changed {
+			on = notificationEnabled(user, kind)
+		}
+		if !on {
+			optOuts = append(optOuts, kind)
+		}
+	}
+	if err := uc.userRepository.SetNotificationOptOuts(userID, optOuts); err != nil {
+		return nil, err
+	}
+	user.NotificationOptOuts = optOuts
+	return notificationPreferences(user), nil
+}
+
+// SendPaymentNotices goes through the unpaid installments of active loans.
+// Borrowers are reminded of installments due within the reminder period and
+// notified of installments past their due date. Each installment is notified
+// at most once per due date, so the job can run as often as needed.
+func (uc *notificationUsecase) SendPaymentNotices(asOf time.Time) (int, error) {
+	reminderDays := domain.DefaultPaymentReminderDays
+	if config, err := config.LoadConfig(); err == nil && config.Notifications.PaymentReminderDays > 0 {
+		reminderDays = config.Notifications.PaymentReminderDays
+	}
+	loans, err := uc.loanRepository.Get(map[string]string{"status": domain.LoanStatusAccepted})
+	if err != nil {
+		return 0, err
+	}
+	today := truncateToDay(asOf)
+	remindBefore := today.AddDate(0, 0, reminderDays+1)
+	sent := 0
+	for _, loan := range loans {
+		for _, installment := range loan.Schedule {
+			if !installment.PaidAt.IsZero() {
+				continue
+			}
+			due := truncateToDay(installment.DueDate)
+			var kind domain.NotificationType
+			switch {
+			case due.Before(today):
+				kind = domain.NotificationPaymentOverdue
+			case due.Before(remindBefore):
+				kind = domain.NotificationPaymentDue
+			default:
+				continue
+			}
+			notified, err := uc.notifyPayment(loan, installment, kind)
+			if err != nil {
+				uc.log("Notification Failure", fmt.Sprintf("Notifying %s of installment %d of loan %s failed: %v", kind, installment.Number, loan.ID, err))
+				continue
+			}
+			if notified {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+func (uc *notificationUsecase) notifyPayment(loan domain.Loan, installment domain.Installment, kind domain.NotificationType) (bool, error) {
+	key := fmt.Sprintf("%s:%s:%d:%s", kind, loan.ID, installment.Number, installment.DueDate.Format("2006-01-02"))
+	notified := false
+	err := uc.transactor.WithTransaction(func(ctx context.Context) error {
+		var err error
+		notified, err = uc.notifier.withContext(ctx).notify(loan.UserID, kind, loan.ID, key, map[string]interface{}{
+			"LoanID":  loan.ID,
+			"Number":  installment.Number,
+			"Payment": installment.Payment,
+			"DueDate": installment.DueDate,
+		})
+		return err
+	})
+	return notified, err
+}
+
+func (uc *notificationUsecase) log(category, message string) {
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  category,
+		Message:   message,
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log notification event: %v\n", err)
+	}
+}
+
+func notificationPreferences(user domain.User) []domain.NotificationPreference {
+	preferences := make([]domain.NotificationPreference, 0, len(domain.NotificationTypes))
+	for _, kind := range domain.NotificationTypes {
+		preferences = append(preferences, domain.NotificationPreference{
+			Type:      kind,
+			Enabled:   notificationEnabled(user, kind),
+			Mandatory: domain.MandatoryNotifications[kind],
+		})
+	}
+	return preferences
+}
+
+func validNotificationType(kind domain.NotificationType) bool {
+	for _, known := range domain.NotificationTypes {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/pkg/infrastructures"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+)
+
+// notifier records borrower notifications in the app and queues them by
+// email. Bind it to the transaction of the change it reports with
+// withContext, so a notification is only kept when the change is saved.
+type notifier struct {
+	userRepository         domain.UserRepository
+	notificationRepository domain.NotificationRepository
+	outboxRepository       domain.EmailOutboxRepository
+}
+
+func newNotifier(db mongoifc.Database) *notifier {
+	return &notifier{
+		userRepository:         repositories.NewUserRepository(db),
+		notificationRepository: repositories.NewNotificationRepository(db),
+		outboxRepository:       repositories.NewEmailOutboxRepository(db),
+	}
+}
+
+func (n *notifier) withContext(ctx context.Context) *notifier {
+	return &notifier{
+		userRepository:         n.userRepository,
+		notificationRepository: n.notificationRepository.WithContext(ctx),
+		outboxRepository:       n.outboxRepository.WithContext(ctx),
+	}
+}
+
+// notify tells the user about an event, identified by key, in their
+// language. It reports whether the user was notified: nothing is sent for
+// deleted accounts, for types the user opted out of, or for events already
+// notified.
+func (n *notifier) notify(userID string, kind domain.NotificationType, loanID, key string, data map[string]interface{}) (bool, error) {
+	user, err := n.userRepository.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if !user.DeletedAt.IsZero() || !notificationEnabled(user, kind) {
+		return false, nil
+	}
+	email, err := infrastructures.RenderEmail(string(kind), user.Language, data)
+	if err != nil {
+		return false, err
+	}
+	_, err = n.notificationRepository.Create(domain.Notification{
+		UserID:    userID,
+		Type:      kind,
+		LoanID:    loanID,
+		Title:     email.Subject,
+		Message:   email.Content,
+		Key:       key,
+		CreatedAt: time.Now(),
+	})
+	if errors.Is(err, repositories.ErrNotificationExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	err = enqueueEmail(n.outboxRepository, domain.EmailMessage{
+		Template: string(kind),
+		To:       []string{user.Email},
+		Subject:  email.Subject,
+		Text:     email.Text,
+		HTML:     email.HTML,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func notificationEnabled(user domain.User, kind domain.NotificationType) bool {
+	if domain.MandatoryNotifications[kind] {
+		return true
+	}
+	for _, optOut := range user.NotificationOptOuts {
+		if optOut == kind {
+			return false
+		}
+	}
+	return true
+}
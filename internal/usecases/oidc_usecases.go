@@ -0,0 +1,253 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/pkg/infrastructures"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrOIDCDisabled is returned when single sign-on is not configured.
+var ErrOIDCDisabled = errors.New("single sign-on is not enabled")
+
+// mfaMethods are the amr values (RFC 8176) that show the provider checked a
+// second factor.
+var mfaMethods = map[string]bool{"mfa": true, "otp": true, "hwk": true}
+
+type oidcUsecase struct {
+	userRepository  domain.UserRepository
+	loginRepository domain.OIDCLoginRepository
+	logRepository   domain.LogRepository
+	sessionUsecase  domain.SessionUsecase
+}
+
+func NewOIDCUsecase(db mongoifc.Database) domain.OIDCUsecase {
+	return &oidcUsecase{
+		userRepository:  repositories.NewUserRepository(db),
+		loginRepository: repositories.NewOIDCLoginRepository(db),
+		logRepository:   repositories.NewLogRepository(db),
+		sessionUsecase:  NewSessionUsecase(db),
+	}
+}
+
+func loadOIDCConfig() (config.OIDC, error) {
+	c, err := config.LoadConfig()
+	if err != nil {
+		return config.OIDC{}, err
+	}
+	if !c.OIDC.Enabled {
+		return config.OIDC{}, ErrOIDCDisabled
+	}
+	if len(c.OIDC.Scopes) == 0 {
+		c.OIDC.Scopes = []string{"openid", "email", "profile"}
+	}
+	if c.OIDC.GroupsClaim == "" {
+		c.OIDC.GroupsClaim = "groups"
+	}
+	return c.OIDC, nil
+}
+
+// StartLogin records a new login with a PKCE verifier and nonce and returns
+// the provider's authorization URL for it.
+func (uc *oidcUsecase) StartLogin() (string, error) {
+	oidc, err := loadOIDCConfig()
+	if err != nil {
+		return "", err
+	}
+	provider, err := infrastructures.DiscoverOIDCProvider(oidc.Issuer)
+	if err != nil {
+		return "", err
+	}
+	state, _, err := infrastructures.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, _, err := infrastructures.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge, err := infrastructures.GeneratePKCE()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	login := domain.OIDCLogin{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(domain.OIDCLoginValidity),
+	}
+	if err := uc.loginRepository.Create(login); err != nil {
+		return "", err
+	}
+	return infrastructures.OIDCAuthorizationURL(provider, oidc.ClientID, oidc.RedirectURL, oidc.Scopes, state, nonce, challenge)
+}
+
+// CompleteLogin exchanges the authorization code, validates the ID token and
+// maps the user's groups to roles. Staff are matched by their provider
+// identity, or by verified email on their first login, and created if they
+// do not exist yet. The provider is authoritative for roles: they are
+// replaced on every login and users without a mapped role are refused.
+func (uc *oidcUsecase) CompleteLogin(state, code string) (domain.User, bool, error) {
+	oidc, err := loadOIDCConfig()
+	if err != nil {
+		return domain.User{}, false, err
+	}
+	login, err := uc.loginRepository.Consume(state)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+	provider, err := infrastructures.DiscoverOIDCProvider(oidc.Issuer)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+	rawToken, err := infrastructures.ExchangeAuthorizationCode(provider, oidc.ClientID, oidc.ClientSecret, oidc.RedirectURL, code, login.CodeVerifier)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+	leeway := time.Duration(0)
+	if c, err := config.LoadConfig(); err == nil {
+		leeway = parseDuration(c.Jwt.ClockSkew, 0)
+	}
+	claims, err := infrastructures.ValidateIDToken(provider, rawToken, oidc.ClientID, login.Nonce, leeway)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+
+	identity := domain.ExternalIdentity{Issuer: provider.Issuer, Subject: claims.Subject}
+	roles := mapGroupsToRoles(oidc.GroupRoles, infrastructures.ClaimStrings(claims.Claims, oidc.GroupsClaim))
+	user, err := uc.findOrCreateUser(identity, claims, len(roles) > 0)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+	if !user.IsActive || !user.SuspendedAt.IsZero() || !user.DeletedAt.IsZero() {
+		return domain.User{}, false, errors.New("user account is not active")
+	}
+	if !sameRoles(user.Roles, roles) {
+		if err := uc.userRepository.SetRoles(user.ID, roles); err != nil {
+			return domain.User{}, false, err
+		}
+		// Tokens of existing sessions still carry the previous roles.
+		if err := uc.sessionUsecase.RevokeAllSessions(user.ID); err != nil {
+			return domain.User{}, false, err
+		}
+		uc.log("Role Assignment", fmt.Sprintf("Roles of user %s set to %v from identity provider groups", user.ID, roles))
+		user.Roles = roles
+		user.IsAdmin = len(roles) > 0
+	}
+	if len(roles) == 0 {
+		uc.log("Login Attempt", fmt.Sprintf("Single sign-on refused for user %s: no role is mapped to their groups", user.ID))
+		return domain.User{}, false, errors.New("your groups do not grant access to this application")
+	}
+
+	mfa := oidc.TrustMFA
+	for _, method := range claims.AMR {
+		if mfaMethods[method] {
+			mfa = true
+		}
+	}
+	uc.log("Login Attempt", fmt.Sprintf("User %s logged in through single sign-on", user.ID))
+	return user, mfa, nil
+}
+
+func (uc *oidcUsecase) findOrCreateUser(identity domain.ExternalIdentity, claims infrastructures.IDTokenClaims, staff bool) (domain.User, error) {
+	user, err := uc.userRepository.GetByExternalIdentity(identity)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, repositories.ErrUserNotFound) {
+		return domain.User{}, err
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return domain.User{}, errors.New("the identity provider did not supply a verified email address")
+	}
+
+	user, err = uc.userRepository.GetByEmail(claims.Email)
+	if err == nil {
+		if err := uc.userRepository.LinkExternalIdentity(user.ID, identity); err != nil {
+			return domain.User{}, err
+		}
+		user.ExternalIdentity = identity
+		uc.log("User Management", fmt.Sprintf("User %s linked to identity provider account %s", user.ID, identity.Subject))
+		return user, nil
+	}
+	if !errors.Is(err, repositories.ErrUserNotFound) {
+		return domain.User{}, err
+	}
+	if !staff {
+		return domain.User{}, errors.New("your groups do not grant access to this application")
+	}
+
+	// Single sign-on users have no password and can only log in through the
+	// provider until they reset one.
+	user, err = uc.userRepository.Create(domain.User{
+		ID:               primitive.NewObjectID().Hex(),
+		Email:            claims.Email,
+		Name:             claims.Name,
+		IsActive:         true,
+		Roles:            []domain.Role{},
+		ExternalIdentity: identity,
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	uc.log("User Registration", fmt.Sprintf("User %s created from identity provider account %s", user.ID, identity.Subject))
+	return user, nil
+}
+
+func (uc *oidcUsecase) log(category, message string) {
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  category,
+		Message:   message,
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log single sign-on event: %v\n", err)
+	}
+}
+
+// mapGroupsToRoles returns the known roles granted by the groups, sorted and
+// without duplicates. Group names are compared case-insensitively since the
+// configuration loader lowercases map keys.
+func mapGroupsToRoles(groupRoles map[string][]string, groups []string) []domain.Role {
+	granted := map[domain.Role]bool{}
+	for _, group := range groups {
+		for _, name := range groupRoles[strings.ToLower(group)] {
+			if role := domain.Role(name); domain.ValidRole(role) {
+				granted[role] = true
+			}
+		}
+	}
+	roles := make([]domain.Role, 0, len(granted))
+	for role := range granted {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i] < roles[j] })
+	return roles
+}
+
+func sameRoles(current, roles []domain.Role) bool {
+	if len(current) != len(roles) {
+		return false
+	}
+	has := map[domain.Role]bool{}
+	for _, role := range current {
+		has[role] = true
+	}
+	for _, role := range roles {
+		if !has[role] {
+			return false
+		}
+	}
+	return true
+}
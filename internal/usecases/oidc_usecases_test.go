@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"loan-management/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestMapGroupsToRoles(t *testing.T) {
+	// Keys are lowercase, as the configuration loader stores them.
+	groupRoles := map[string][]string{
+		"loan-officers": {"loan_officer"},
+		"risk":          {"underwriter", "auditor"},
+		"it-admins":     {"super_admin", "root"},
+		"auditors":      {"auditor"},
+	}
+	tests := []struct {
+		name   string
+		groups []string
+		want   []domain.Role
+	}{
+		{"no groups", nil, []domain.Role{}},
+		{"unmapped groups", []string{"everyone", "contractors"}, []domain.Role{}},
+		{"single group", []string{"loan-officers"}, []domain.Role{domain.RoleLoanOfficer}},
+		{"group names are case-insensitive", []string{"Loan-Officers"}, []domain.Role{domain.RoleLoanOfficer}},
+		{"roles are sorted and deduplicated", []string{"auditors", "risk", "RISK"}, []domain.Role{domain.RoleAuditor, domain.RoleUnderwriter}},
+		{"unknown roles are ignored", []string{"it-admins"}, []domain.Role{domain.RoleSuperAdmin}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapGroupsToRoles(groupRoles, tt.groups)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mapGroupsToRoles(%v) = %v, want %v", tt.groups, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	"loan-management/internal/domain"
+	"loan-management/pkg/infrastructures"
+	"time"
+)
+
+// issueToken creates a single-use token for purpose, invalidating any token
+// previously issued to the user for the same purpose, and returns the
+// plaintext to email to the given address.
+func (uc *userUsecase) issueToken(userID, email, purpose string, ttl time.Duration) (string, error) {
+	if err := uc.tokenRepository.Invalidate(userID, purpose); err != nil {
+		return "", err
+	}
+	token, hash, err := infrastructures.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	_, err = uc.tokenRepository.Create(domain.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		Email:     email,
+		TokenHash: hash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// findToken looks up a token for purpose without redeeming it, so it can be
+// checked before anything else is done with the request.
+func (uc *userUsecase) findToken(token, purpose string) (domain.OneTimeToken, error) {
+	return uc.tokenRepository.Find(infrastructures.HashOpaqueToken(token), purpose)
+}
+
+// consumeToken redeems a token for purpose. A token can only be redeemed once.
+func (uc *userUsecase) consumeToken(token, purpose string) (domain.OneTimeToken, error) {
+	return uc.tokenRepository.Consume(infrastructures.HashOpaqueToken(token), purpose)
+}
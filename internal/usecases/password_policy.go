@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/pkg/infrastructures"
+	"strings"
+	"unicode"
+)
+
+// maxPasswordBytes is the longest password bcrypt can hash.
+const maxPasswordBytes = 72
+
+// checkPassword enforces the configured password policy for the user's new
+// password: length, character classes, no email or name, and not present in
+// the breached-password list.
+func checkPassword(password string, user domain.User) error {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	policy := config.Password
+	minLength := policy.MinLength
+	if minLength <= 0 {
+		minLength = domain.DefaultPasswordMinLength
+	}
+
+	if len([]rune(password)) < minLength {
+		return fmt.Errorf("password must be at least %d characters long", minLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("password must be at most %d bytes long", maxPasswordBytes)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	switch {
+	case policy.RequireUpper && !upper:
+		return errors.New("password must contain an uppercase letter")
+	case policy.RequireLower && !lower:
+		return errors.New("password must contain a lowercase letter")
+	case policy.RequireDigit && !digit:
+		return errors.New("password must contain a digit")
+	case policy.RequireSymbol && !symbol:
+		return errors.New("password must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	local, _, _ := strings.Cut(strings.ToLower(user.Email), "@")
+	if len(local) >= 3 && strings.Contains(lowered, local) {
+		return errors.New("password must not contain your email address")
+	}
+	for _, part := range strings.Fields(strings.ToLower(user.Name)) {
+		if len(part) >= 3 && strings.Contains(lowered, part) {
+			return errors.New("password must not contain your name")
+		}
+	}
+
+	if policy.BreachedHashesFile != "" {
+		breached, err := infrastructures.IsBreachedPassword(policy.BreachedHashesFile, password)
+		if err != nil {
+			return fmt.Errorf("checking breached passwords: %v", err)
+		}
+		if breached {
+			return errors.New("password has appeared in a data breach, please choose another one")
+		}
+	}
+	return nil
+}
+
+// passwordHistorySize is how many previous passwords cannot be reused.
+func passwordHistorySize() int {
+	config, err := config.LoadConfig()
+	if err != nil || config.Password.HistorySize <= 0 {
+		return domain.DefaultPasswordHistorySize
+	}
+	return config.Password.HistorySize
+}
+
+// rotatePassword checks the new password against the user's current and
+// previous passwords and returns the update that sets it, remembering the
+// current password in the history.
+func rotatePassword(user domain.User, password string) (domain.UserUpdate, error) {
+	size := passwordHistorySize()
+	previous := append([]string{user.Password}, user.PasswordHistory...)
+	if len(previous) > size {
+		previous = previous[:size]
+	}
+	for _, hash := range previous {
+		if hash != "" && infrastructures.ComparePassword(hash, password) {
+			return domain.UserUpdate{}, fmt.Errorf("password must differ from your last %d passwords", size)
+		}
+	}
+	hashedPassword, err := infrastructures.HashPassword(password)
+	if err != nil {
+		return domain.UserUpdate{}, err
+	}
+	history := []string{}
+	for _, hash := range previous {
+		if hash != "" {
+			history = append(history, hash)
+		}
+	}
+	return domain.UserUpdate{Password: &hashedPassword, PasswordHistory: &history}, nil
+}
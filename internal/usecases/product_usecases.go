@@ -0,0 +1,190 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrProductInUse is returned when deleting a product loans refer to.
+var ErrProductInUse = errors.New("loan product is in use")
+
+type loanProductUsecase struct {
+	productRepository domain.LoanProductRepository
+	loanRepository    domain.LoanRepository
+	logRepository     domain.LogRepository
+}
+
+func NewLoanProductUsecase(db mongoifc.Database) domain.LoanProductUsecase {
+	return &loanProductUsecase{
+		productRepository: repositories.NewLoanProductRepository(db),
+		loanRepository:    repositories.NewLoanRepository(db),
+		logRepository:     repositories.NewLogRepository(db),
+	}
+}
+
+func (uc *loanProductUsecase) CreateProduct(product domain.LoanProduct) (domain.LoanProduct, error) {
+	if product.DayCountConvention == "" {
+		product.DayCountConvention = domain.DayCountActual365
+	}
+	if err := validateProduct(product); err != nil {
+		return domain.LoanProduct{}, err
+	}
+	product.CreatedAt = time.Now()
+	createdProduct, err := uc.productRepository.Create(product)
+	if err != nil {
+		return domain.LoanProduct{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Loan Product Creation",
+		Message:   fmt.Sprintf("Loan product %s (%s) was created", createdProduct.ID, createdProduct.Name),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log loan product creation: %v\n", err)
+	}
+
+	return createdProduct, nil
+}
+
+func (uc *loanProductUsecase) UpdateProduct(id string, product domain.LoanProduct) (domain.LoanProduct, error) {
+	existing, err := uc.productRepository.GetByID(id)
+	if err != nil {
+		return domain.LoanProduct{}, err
+	}
+	merged := existing
+	if product.Name != "" {
+		merged.Name = product.Name
+	}
+	if product.RateType != "" {
+		merged.RateType = product.RateType
+	}
+	if product.FixedRate != 0 {
+		merged.FixedRate = product.FixedRate
+	}
+	if product.Benchmark != "" {
+		merged.Benchmark = product.Benchmark
+	}
+	if product.Margin != 0 {
+		merged.Margin = product.Margin
+	}
+	if product.ResetFrequencyMonths != 0 {
+		merged.ResetFrequencyMonths = product.ResetFrequencyMonths
+	}
+	if product.TermMonths != 0 {
+		merged.TermMonths = product.TermMonths
+	}
+	if product.OriginationFee != 0 {
+		merged.OriginationFee = product.OriginationFee
+	}
+	if product.OriginationFeeRate != 0 {
+		merged.OriginationFeeRate = product.OriginationFeeRate
+	}
+	if product.DayCountConvention != "" {
+		merged.DayCountConvention = product.DayCountConvention
+	}
+	if err := validateProduct(merged); err != nil {
+		return domain.LoanProduct{}, err
+	}
+	updatedProduct, err := uc.productRepository.Update(id, product)
+	if err != nil {
+		return domain.LoanProduct{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Loan Product Update",
+		Message:   fmt.Sprintf("Loan product %s was updated", id),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log loan product update: %v\n", err)
+	}
+
+	return updatedProduct, nil
+}
+
+// DeleteProduct removes a product no loan was taken out under. Loans keep
+// referring to their product for its terms, so such products stay.
+func (uc *loanProductUsecase) DeleteProduct(id string) error {
+	loans, err := uc.loanRepository.CountByProduct(id)
+	if err != nil {
+		return err
+	}
+	if loans > 0 {
+		return fmt.Errorf("%w, %d loans refer to it", ErrProductInUse, loans)
+	}
+	if err := uc.productRepository.Delete(id); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Loan Product Deletion",
+		Message:   fmt.Sprintf("Loan product %s was deleted", id),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log loan product deletion: %v\n", err)
+	}
+
+	return nil
+}
+
+func (uc *loanProductUsecase) GetProducts() ([]domain.LoanProduct, error) {
+	return uc.productRepository.Get()
+}
+
+func (uc *loanProductUsecase) GetProduct(id string) (domain.LoanProduct, error) {
+	return uc.productRepository.GetByID(id)
+}
+
+func validateProduct(product domain.LoanProduct) error {
+	if _, err := dayCountFraction(product.DayCountConvention, time.Now(), time.Now()); err != nil {
+		return err
+	}
+	if product.TermMonths < 0 {
+		return fmt.Errorf("term cannot be negative")
+	}
+	if max := maxTermMonths(); product.TermMonths > max {
+		return fmt.Errorf("%w, the longest term is %d months", ErrTermTooLong, max)
+	}
+	if product.OriginationFee < 0 || product.OriginationFeeRate < 0 {
+		return fmt.Errorf("fees cannot be negative")
+	}
+	switch product.RateType {
+	case domain.RateTypeFixed:
+		if product.FixedRate < 0 {
+			return fmt.Errorf("fixed rate cannot be negative")
+		}
+	case domain.RateTypeVariable:
+		if product.Benchmark == "" {
+			return fmt.Errorf("variable-rate products require a benchmark")
+		}
+		if product.ResetFrequencyMonths <= 0 {
+			return fmt.Errorf("variable-rate products require a reset frequency")
+		}
+	default:
+		return fmt.Errorf("invalid rate type %s", product.RateType)
+	}
+	return nil
+}
+
+// productRate returns the rate a product charges on the given date.
+func productRate(product domain.LoanProduct, benchmarkRepository domain.BenchmarkRateRepository, date time.Time) (float64, error) {
+	if product.RateType != domain.RateTypeVariable {
+		return product.FixedRate, nil
+	}
+	benchmark, err := benchmarkRepository.GetEffective(product.Benchmark, date)
+	if err != nil {
+		return 0, fmt.Errorf("no %s rate effective on %s", product.Benchmark, date.Format("2006-01-02"))
+	}
+	return benchmark.Rate + product.Margin, nil
+}
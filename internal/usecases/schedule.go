@@ -0,0 +1,89 @@
+package usecases
+
+import (
+	"loan-management/internal/domain"
+	"math"
+	"time"
+)
+
+// buildAmortizationSchedule splits a loan into termMonths equal monthly
+// payments, the first falling due one month after start.
+func buildAmortizationSchedule(principal, annualRate float64, termMonths int, start time.Time) []domain.Installment {
+	dueDates := make([]time.Time, termMonths)
+	for i := range dueDates {
+		dueDates[i] = addMonths(truncateToDay(start), i+1)
+	}
+	return amortize(principal, annualRate, dueDates, 1)
+}
+
+// regenerateSchedule keeps the installments due on or before from and
+// re-amortizes the outstanding balance over the remaining due dates at the new
+// rate. Payments already recorded for remaining installments are kept.
+func regenerateSchedule(schedule []domain.Installment, annualRate float64, from time.Time) []domain.Installment {
+	idx := 0
+	for idx < len(schedule) && !schedule[idx].DueDate.After(from) {
+		idx++
+	}
+	if idx == len(schedule) {
+		return schedule
+	}
+	balance := schedule[idx].Balance + schedule[idx].Principal
+	dueDates := make([]time.Time, 0, len(schedule)-idx)
+	for _, installment := range schedule[idx:] {
+		dueDates = append(dueDates, installment.DueDate)
+	}
+	remaining := amortize(balance, annualRate, dueDates, schedule[idx].Number)
+	for i := range remaining {
+		remaining[i].PaidAt = schedule[idx+i].PaidAt
+	}
+	return append(schedule[:idx:idx], remaining...)
+}
+
+func amortize(balance, annualRate float64, dueDates []time.Time, firstNumber int) []domain.Installment {
+	n := len(dueDates)
+	if n == 0 {
+		return nil
+	}
+	monthlyRate := annualRate / 12
+	payment := balance / float64(n)
+	if monthlyRate != 0 {
+		payment = balance * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(n)))
+	}
+	payment = roundCents(payment)
+
+	installments := make([]domain.Installment, 0, n)
+	for i, dueDate := range dueDates {
+		interest := roundCents(balance * monthlyRate)
+		principal := payment - interest
+		if i == n-1 {
+			principal = balance
+		}
+		balance = roundCents(balance - principal)
+		installments = append(installments, domain.Installment{
+			Number:    firstNumber + i,
+			DueDate:   dueDate,
+			Payment:   roundCents(principal + interest),
+			Principal: roundCents(principal),
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+	return installments
+}
+
+// addMonths adds months to t, clamping to the last day of the resulting month
+// so that a loan started on the 31st falls due on the 30th or 28th rather than
+// spilling into the following month.
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	firstOfMonth := time.Date(y, m+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if d > lastDay {
+		d = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
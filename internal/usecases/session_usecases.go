@@ -0,0 +1,270 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/pkg/infrastructures"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultAccessTokenTTL  = 1 * time.Hour
+	defaultRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+)
+
+type sessionUsecase struct {
+	sessionRepository      domain.SessionRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	userRepository         domain.UserRepository
+	logRepository          domain.LogRepository
+	signingKeyUsecase      domain.SigningKeyUsecase
+}
+
+func NewSessionUsecase(db mongoifc.Database) domain.SessionUsecase {
+	return &sessionUsecase{
+		sessionRepository:      repositories.NewSessionRepository(db),
+		refreshTokenRepository: repositories.NewRefreshTokenRepository(db),
+		userRepository:         repositories.NewUserRepository(db),
+		logRepository:          repositories.NewLogRepository(db),
+		signingKeyUsecase:      NewSigningKeyUsecase(db),
+	}
+}
+
+// CreateSession starts a session for a device and returns its first token pair.
+// The mfa flag records whether the login passed a second factor.
+func (uc *sessionUsecase) CreateSession(user domain.User, device, ip string, mfa bool) (domain.TokenPair, error) {
+	accessTTL, refreshTTL, err := tokenTTLs()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	now := time.Now()
+	session, err := uc.sessionRepository.Create(domain.Session{
+		UserID:     user.ID,
+		Device:     device,
+		IP:         ip,
+		MFA:        mfa,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(refreshTTL),
+	})
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	return uc.issueTokens(user, session, accessTTL, refreshTTL)
+}
+
+// Refresh exchanges a refresh token for a new token pair. Every refresh token
+// is single-use: presenting one that was already used revokes its session.
+func (uc *sessionUsecase) Refresh(refreshToken, ip string) (domain.TokenPair, error) {
+	token, err := uc.refreshTokenRepository.GetByHash(infrastructures.HashOpaqueToken(refreshToken))
+	if err != nil {
+		return domain.TokenPair{}, ErrInvalidRefreshToken
+	}
+	session, err := uc.sessionRepository.GetByID(token.SessionID)
+	if err != nil || !session.RevokedAt.IsZero() {
+		return domain.TokenPair{}, ErrInvalidRefreshToken
+	}
+	if !token.UsedAt.IsZero() {
+		uc.revokeOnReuse(session, ip)
+		return domain.TokenPair{}, ErrInvalidRefreshToken
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return domain.TokenPair{}, fmt.Errorf("refresh token has expired")
+	}
+	consumed, err := uc.refreshTokenRepository.MarkUsed(token.ID)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	if !consumed {
+		uc.revokeOnReuse(session, ip)
+		return domain.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	user, err := uc.userRepository.GetByID(token.UserID)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	if !user.SuspendedAt.IsZero() || !user.DeletedAt.IsZero() {
+		return domain.TokenPair{}, ErrSessionRevoked
+	}
+	accessTTL, refreshTTL, err := tokenTTLs()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	if err := uc.sessionRepository.Touch(session.ID, ip); err != nil {
+		return domain.TokenPair{}, err
+	}
+	return uc.issueTokens(user, session, accessTTL, refreshTTL)
+}
+
+// ValidateSession checks that the session an access token was issued for is
+// still active and that its user was neither suspended nor deleted since.
+func (uc *sessionUsecase) ValidateSession(sessionID, userID string) error {
+	if sessionID == "" {
+		return ErrSessionRevoked
+	}
+	session, err := uc.sessionRepository.GetByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return ErrSessionRevoked
+	}
+	if !session.RevokedAt.IsZero() || time.Now().After(session.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil || !user.SuspendedAt.IsZero() || !user.DeletedAt.IsZero() {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+func (uc *sessionUsecase) GetSessions(userID, currentSessionID string) ([]domain.Session, error) {
+	sessions, err := uc.sessionRepository.GetActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's sessions. Its access tokens are
+// rejected from then on and its refresh tokens are deleted.
+func (uc *sessionUsecase) RevokeSession(userID, sessionID string) error {
+	session, err := uc.sessionRepository.GetByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return repositories.ErrSessionNotFound
+	}
+	if err := uc.sessionRepository.Revoke(sessionID); err != nil {
+		return err
+	}
+	if err := uc.refreshTokenRepository.DeleteBySessionID(sessionID); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Session Revocation",
+		Message:   fmt.Sprintf("User %s ended session %s", userID, sessionID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log session revocation: %v\n", err)
+	}
+
+	return nil
+}
+
+// RevokeAllSessions ends every session of the user, forcing them to log in
+// again. Their refresh tokens are rejected once the session is revoked.
+func (uc *sessionUsecase) RevokeAllSessions(userID string) error {
+	if err := uc.sessionRepository.RevokeByUserID(userID, ""); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Session Revocation",
+		Message:   fmt.Sprintf("All sessions of user %s were revoked", userID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log session revocation: %v\n", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions signs the user out of every device except the current
+// one.
+func (uc *sessionUsecase) RevokeOtherSessions(userID, currentSessionID string) error {
+	if err := uc.sessionRepository.RevokeByUserID(userID, currentSessionID); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Session Revocation",
+		Message:   fmt.Sprintf("Sessions of user %s other than %s were revoked", userID, currentSessionID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log session revocation: %v\n", err)
+	}
+
+	return nil
+}
+
+func (uc *sessionUsecase) issueTokens(user domain.User, session domain.Session, accessTTL, refreshTTL time.Duration) (domain.TokenPair, error) {
+	accessToken, err := uc.signingKeyUsecase.SignAccessToken(user, session, accessTTL)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	refreshToken, hash, err := infrastructures.GenerateOpaqueToken()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	expiresAt := time.Now().Add(refreshTTL)
+	if expiresAt.After(session.ExpiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+	_, err = uc.refreshTokenRepository.Create(domain.RefreshToken{
+		SessionID: session.ID,
+		UserID:    user.ID,
+		TokenHash: hash,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	return domain.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// revokeOnReuse revokes the whole token family of a session after one of its
+// consumed refresh tokens was presented again, as the token may be stolen.
+func (uc *sessionUsecase) revokeOnReuse(session domain.Session, ip string) {
+	if err := uc.sessionRepository.Revoke(session.ID); err != nil {
+		fmt.Printf("Failed to revoke session %s: %v\n", session.ID, err)
+	}
+	if err := uc.refreshTokenRepository.DeleteBySessionID(session.ID); err != nil {
+		fmt.Printf("Failed to delete refresh tokens of session %s: %v\n", session.ID, err)
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Refresh Token Reuse",
+		Message:   fmt.Sprintf("Reused refresh token of user %s presented from %s, session %s revoked", session.UserID, ip, session.ID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log refresh token reuse: %v\n", err)
+	}
+}
+
+func tokenTTLs() (time.Duration, time.Duration, error) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return 0, 0, err
+	}
+	accessTTL, err := time.ParseDuration(config.Jwt.AccessTokenTTL)
+	if err != nil || accessTTL <= 0 {
+		accessTTL = defaultAccessTokenTTL
+	}
+	refreshTTL, err := time.ParseDuration(config.Jwt.RefreshTokenTTL)
+	if err != nil || refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+	return accessTTL, refreshTTL, nil
+}
@@ -0,0 +1,225 @@
+package usecases
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"loan-management/internal/repositories"
+	"loan-management/pkg/infrastructures"
+	"sync"
+	"time"
+
+	"github.com/sv-tools/mongoifc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultKeyRotationInterval = 30 * 24 * time.Hour
+	signingKeyCacheTTL         = time.Minute
+)
+
+type signingKeyUsecase struct {
+	signingKeyRepository domain.SigningKeyRepository
+	logRepository        domain.LogRepository
+
+	mu       sync.Mutex
+	keys     []domain.SigningKey
+	loadedAt time.Time
+}
+
+func NewSigningKeyUsecase(db mongoifc.Database) domain.SigningKeyUsecase {
+	return &signingKeyUsecase{
+		signingKeyRepository: repositories.NewSigningKeyRepository(db),
+		logRepository:        repositories.NewLogRepository(db),
+	}
+}
+
+// Rotate creates a new signing key and retires the previous ones. Retired keys
+// keep verifying tokens for one access token lifetime.
+func (uc *signingKeyUsecase) Rotate() (domain.SigningKey, error) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	algorithm := config.Jwt.Algorithm
+	if algorithm == "" {
+		algorithm = infrastructures.AlgorithmRS256
+	}
+	privatePEM, publicPEM, err := infrastructures.GenerateSigningKey(algorithm)
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	encryptedPrivate, err := infrastructures.EncryptSecret(privatePEM)
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	previous, err := uc.signingKeyRepository.GetValid()
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	key, err := uc.signingKeyRepository.Create(domain.SigningKey{
+		Algorithm:  algorithm,
+		PrivateKey: encryptedPrivate,
+		PublicKey:  publicPEM,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	accessTTL, _, err := tokenTTLs()
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	now := time.Now()
+	for _, k := range previous {
+		if k.RetiredAt.IsZero() {
+			if err := uc.signingKeyRepository.Retire(k.ID, now, now.Add(accessTTL)); err != nil {
+				return domain.SigningKey{}, err
+			}
+		}
+	}
+	uc.mu.Lock()
+	uc.keys = nil
+	uc.mu.Unlock()
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Signing Key Rotation",
+		Message:   fmt.Sprintf("Signing key %s (%s) was created", key.ID, algorithm),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log signing key rotation: %v\n", err)
+	}
+
+	return key, nil
+}
+
+// RotateIfDue rotates the signing key when there is none yet or the current
+// one is older than the configured rotation interval.
+func (uc *signingKeyUsecase) RotateIfDue(now time.Time) (bool, error) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return false, err
+	}
+	interval, err := time.ParseDuration(config.Jwt.KeyRotationInterval)
+	if err != nil || interval <= 0 {
+		interval = defaultKeyRotationInterval
+	}
+	keys, err := uc.signingKeyRepository.GetValid()
+	if err != nil {
+		return false, err
+	}
+	for _, k := range keys {
+		if k.RetiredAt.IsZero() && now.Sub(k.CreatedAt) < interval {
+			return false, nil
+		}
+	}
+	if _, err := uc.Rotate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (uc *signingKeyUsecase) GetValidKeys() ([]domain.SigningKey, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.keys != nil && time.Since(uc.loadedAt) < signingKeyCacheTTL {
+		return uc.keys, nil
+	}
+	keys, err := uc.signingKeyRepository.GetValid()
+	if err != nil {
+		return nil, err
+	}
+	uc.keys = keys
+	uc.loadedAt = time.Now()
+	return keys, nil
+}
+
+func (uc *signingKeyUsecase) SignAccessToken(user domain.User, session domain.Session, ttl time.Duration) (string, error) {
+	key, err := uc.activeKey()
+	if err != nil {
+		return "", err
+	}
+	privatePEM, err := infrastructures.DecryptSecret(key.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := infrastructures.ParsePrivateKey(privatePEM)
+	if err != nil {
+		return "", err
+	}
+	return infrastructures.GenerateJWTToken(user, session, ttl, infrastructures.JWTSigningKey{
+		ID:         key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: privateKey,
+	})
+}
+
+// VerifyAccessToken accepts tokens signed by any key that is still valid.
+func (uc *signingKeyUsecase) VerifyAccessToken(token string) (domain.AccessClaims, error) {
+	claims, err := infrastructures.ValidateJWTToken(token, uc.lookupPublicKey)
+	if err != nil {
+		return domain.AccessClaims{}, err
+	}
+	return domain.AccessClaims{
+		UserID:    claims.Subject,
+		Email:     claims.Email,
+		IsAdmin:   claims.IsAdmin,
+		SessionID: claims.SessionID,
+		MFA:       claims.MFA,
+		Roles:     claims.Roles,
+	}, nil
+}
+
+// activeKey returns the newest unretired key, creating the first one on a
+// fresh deployment.
+func (uc *signingKeyUsecase) activeKey() (domain.SigningKey, error) {
+	keys, err := uc.GetValidKeys()
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+	for _, k := range keys {
+		if k.RetiredAt.IsZero() {
+			return k, nil
+		}
+	}
+	return uc.Rotate()
+}
+
+func (uc *signingKeyUsecase) lookupPublicKey(kid string) (crypto.PublicKey, string, error) {
+	find := func(keys []domain.SigningKey) (domain.SigningKey, bool) {
+		for _, k := range keys {
+			if k.ID == kid {
+				return k, true
+			}
+		}
+		return domain.SigningKey{}, false
+	}
+	keys, err := uc.GetValidKeys()
+	if err != nil {
+		return nil, "", err
+	}
+	key, ok := find(keys)
+	if !ok {
+		// The key may have been created by another instance since the cache
+		// was filled.
+		keys, err = uc.signingKeyRepository.GetValid()
+		if err != nil {
+			return nil, "", err
+		}
+		if key, ok = find(keys); !ok {
+			return nil, "", errors.New("unknown signing key")
+		}
+	}
+	if !key.RetiredAt.IsZero() && time.Now().After(key.ExpiresAt) {
+		return nil, "", errors.New("signing key has expired")
+	}
+	publicKey, err := infrastructures.ParsePublicKey(key.PublicKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return publicKey, key.Algorithm, nil
+}
@@ -1,47 +1,108 @@
 package usecases
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"loan-management/internal/domain"
 	"loan-management/internal/repositories"
 	"loan-management/pkg/infrastructures"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/sv-tools/mongoifc"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// ErrResendThrottled is returned when verification emails are requested too
+// often.
+var ErrResendThrottled = errors.New("too many verification emails requested")
+
+const (
+	mfaChallengeTTL   = 5 * time.Minute
+	mfaIssuer         = "Loan Manager"
+	recoveryCodeCount = 10
+)
+
 type userUsecase struct {
-	userRepository domain.UserRepository
-	logRepository  domain.LogRepository
+	userRepository     domain.UserRepository
+	logRepository      domain.LogRepository
+	throttleRepository domain.LoginThrottleRepository
+	tokenRepository    domain.OneTimeTokenRepository
+	outboxRepository   domain.EmailOutboxRepository
+	apiKeyRepository   domain.APIKeyRepository
+	transactor         domain.Transactor
+	sessionUsecase     domain.SessionUsecase
 }
 
 func NewUserUsecase(db mongoifc.Database) domain.UserUsecases {
 	userRepo := repositories.NewUserRepository(db)
 	logRepo := repositories.NewLogRepository(db)
 	return &userUsecase{
-		userRepository: userRepo,
-		logRepository:  logRepo,
+		userRepository:     userRepo,
+		logRepository:      logRepo,
+		throttleRepository: repositories.NewLoginThrottleRepository(db),
+		tokenRepository:    repositories.NewOneTimeTokenRepository(db),
+		outboxRepository:   repositories.NewEmailOutboxRepository(db),
+		apiKeyRepository:   repositories.NewAPIKeyRepository(db),
+		transactor:         repositories.NewTransactor(db),
+		sessionUsecase:     NewSessionUsecase(db),
 	}
 }
 
-func (uc *userUsecase) Register(user domain.User) (domain.User, error) {
-	hashedPassword, err := infrastructures.HashPassword(user.Password)
-	if err != nil {
+// inTransaction runs fn with a copy of the usecase whose user, token and
+// outbox repositories share one transaction, so an email is only queued
+// when the change that caused it is saved.
+func (uc *userUsecase) inTransaction(fn func(tx *userUsecase) error) error {
+	return uc.transactor.WithTransaction(func(ctx context.Context) error {
+		tx := *uc
+		tx.userRepository = uc.userRepository.WithContext(ctx)
+		tx.tokenRepository = uc.tokenRepository.WithContext(ctx)
+		tx.outboxRepository = uc.outboxRepository.WithContext(ctx)
+		return fn(&tx)
+	})
+}
+
+// Register creates an unverified account. Only the email, name, language and
+// password of the submitted user are used, so registration can never grant
+// admin access, roles or MFA settings.
+func (uc *userUsecase) Register(registration domain.User) (domain.User, error) {
+	if err := checkPassword(registration.Password, registration); err != nil {
 		return domain.User{}, err
 	}
-	user.Password = hashedPassword
-	user.ID = primitive.NewObjectIDFromTimestamp(time.Now()).Hex()
-	expirationTime := time.Now().Add(1 * time.Hour)
-	verificationToken, err := infrastructures.GenerateVerificationToken(user.ID, user.Email, "emailVerification", expirationTime)
+	if !validLanguage(registration.Language) {
+		return domain.User{}, errors.New("language must be a language tag such as en or fr-CA")
+	}
+	hashedPassword, err := infrastructures.HashPassword(registration.Password)
 	if err != nil {
-		return domain.User{}, fmt.Errorf("generating token: %v", err)
+		return domain.User{}, err
 	}
-
-	go infrastructures.SendVerificationEmail(user.Email, verificationToken)
-
-	createdUser, err := uc.userRepository.Create(user)
+	user := domain.User{
+		ID:       primitive.NewObjectIDFromTimestamp(time.Now()).Hex(),
+		Email:    registration.Email,
+		Name:     registration.Name,
+		Language: registration.Language,
+		Password: hashedPassword,
+	}
+	var createdUser domain.User
+	err = uc.inTransaction(func(tx *userUsecase) error {
+		createdUser, err = tx.userRepository.Create(user)
+		if err != nil {
+			return err
+		}
+		verificationToken, err := tx.issueToken(user.ID, user.Email, domain.TokenEmailVerification, 1*time.Hour)
+		if err != nil {
+			return fmt.Errorf("generating token: %v", err)
+		}
+		message, err := infrastructures.VerificationEmail(user.Email, user.Language, verificationToken)
+		if err != nil {
+			return err
+		}
+		return enqueueEmail(tx.outboxRepository, message)
+	})
 	if err != nil {
 		return domain.User{}, err
 	}
@@ -62,31 +123,117 @@ func (uc *userUsecase) Register(user domain.User) (domain.User, error) {
 
 // VerifyEmail verifies the user's email with the token
 func (uc *userUsecase) VerifyEmail(token, email string) error {
-	user, err := uc.userRepository.GetByEmail(email)
+	verification, err := uc.consumeToken(token, domain.TokenEmailVerification)
 	if err != nil {
 		return err
 	}
-	if err := infrastructures.ValidateVerificationToken(token, email, user.ID, "emailVerification"); err != nil {
+	user, err := uc.userRepository.GetByID(verification.UserID)
+	if err != nil {
 		return err
 	}
-	if _, err := uc.userRepository.Update(user.ID, domain.User{IsActive: true}); err != nil {
+	if verification.Email != email || user.Email != email {
+		return repositories.ErrInvalidOneTimeToken
+	}
+	if !user.SuspendedAt.IsZero() || !user.DeletedAt.IsZero() {
+		return repositories.ErrInvalidOneTimeToken
+	}
+	active := true
+	if _, err := uc.userRepository.Update(user.ID, domain.UserUpdate{IsActive: &active}); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (uc *userUsecase) Login(email, password string) (domain.User, error) {
+// ResendVerification emails a new verification link to an unverified user,
+// invalidating the previous one. It reports success for unknown, already
+// verified, suspended or deleted accounts so it cannot be used to probe for
+// accounts.
+func (uc *userUsecase) ResendVerification(email string) error {
 	user, err := uc.userRepository.GetByEmail(email)
+	if err != nil || user.IsActive || !user.SuspendedAt.IsZero() || !user.DeletedAt.IsZero() {
+		return nil
+	}
+	latest, err := uc.tokenRepository.GetLatest(user.ID, domain.TokenEmailVerification)
 	if err != nil {
-		return user, err
+		return err
 	}
-	if !user.IsActive {
-		return domain.User{}, fmt.Errorf("user account is not activated")
+	if time.Since(latest.CreatedAt) < domain.VerificationResendCooldown {
+		return fmt.Errorf("%w, please wait a minute before requesting another verification email", ErrResendThrottled)
 	}
-	if !infrastructures.ComparePassword(user.Password, password) {
-		return domain.User{}, fmt.Errorf("incorrect password or email")
+	sent, err := uc.tokenRepository.CountIssuedSince(user.ID, domain.TokenEmailVerification, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if sent >= domain.MaxVerificationResendsPerHour {
+		return fmt.Errorf("%w, please try again later", ErrResendThrottled)
+	}
+	err = uc.inTransaction(func(tx *userUsecase) error {
+		token, err := tx.issueToken(user.ID, user.Email, domain.TokenEmailVerification, 1*time.Hour)
+		if err != nil {
+			return err
+		}
+		message, err := infrastructures.VerificationEmail(user.Email, user.Language, token)
+		if err != nil {
+			return err
+		}
+		return enqueueEmail(tx.outboxRepository, message)
+	})
+	if err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "User Registration",
+		Message:   fmt.Sprintf("Verification email resent to user %s", user.ID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log verification resend: %v\n", err)
 	}
 
+	return nil
+}
+
+// Login checks the user's password. When the user has MFA enabled it returns
+// a short-lived challenge token instead of completing the login; the caller
+// must exchange it through VerifyMFA together with a second factor. Failed
+// attempts are throttled per account and per client IP.
+func (uc *userUsecase) Login(email, password, ip string) (domain.User, string, error) {
+	if err := uc.checkLoginThrottle(email, ip); err != nil {
+		return domain.User{}, "", err
+	}
+	user, err := uc.userRepository.GetByEmail(email)
+	if err != nil || !infrastructures.ComparePassword(user.Password, password) {
+		uc.recordLoginFailure(email, ip)
+		return domain.User{}, "", fmt.Errorf("incorrect password or email")
+	}
+	if err := checkAccountState(user); err != nil {
+		return domain.User{}, "", err
+	}
+
+	if user.MFA.Enabled {
+		challenge, err := uc.issueToken(user.ID, user.Email, domain.TokenMFAChallenge, mfaChallengeTTL)
+		if err != nil {
+			return domain.User{}, "", err
+		}
+		log := domain.SystemLog{
+			ID:        primitive.NewObjectID().Hex(),
+			Timestamp: time.Now(),
+			Category:  "Login Attempt",
+			Message:   fmt.Sprintf("User %s passed password check, awaiting second factor", user.ID),
+		}
+		if err := uc.logRepository.Create(log); err != nil {
+			fmt.Printf("Failed to log login attempt: %v\n", err)
+		}
+		return user, challenge, nil
+	}
+
+	// The failure count is only cleared once the login is complete, so with
+	// MFA it keeps counting wrong codes across repeated password logins.
+	if err := uc.throttleRepository.Reset(accountThrottleKey(email)); err != nil {
+		fmt.Printf("Failed to reset login throttle: %v\n", err)
+	}
 	log := domain.SystemLog{
 		ID:        primitive.NewObjectID().Hex(),
 		Timestamp: time.Now(),
@@ -97,9 +244,214 @@ func (uc *userUsecase) Login(email, password string) (domain.User, error) {
 		fmt.Printf("Failed to log successful login attempt: %v\n", err)
 	}
 
+	return user, "", nil
+}
+
+// VerifyMFA completes a login started by Login. The code may be a TOTP code or
+// one of the user's unused recovery codes. The challenge can be used for one
+// login only, and the account is checked again as it may have changed since
+// the password was.
+func (uc *userUsecase) VerifyMFA(challengeToken, code, ip string) (domain.User, error) {
+	challenge, err := uc.findToken(challengeToken, domain.TokenMFAChallenge)
+	if err != nil {
+		return domain.User{}, errors.New("invalid or expired mfa token")
+	}
+	user, err := uc.userRepository.GetByID(challenge.UserID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if user.Email != challenge.Email || !user.MFA.Enabled {
+		return domain.User{}, errors.New("invalid or expired mfa token")
+	}
+	if err := checkAccountState(user); err != nil {
+		return domain.User{}, err
+	}
+	if err := uc.checkLoginThrottle(user.Email, ip); err != nil {
+		return domain.User{}, err
+	}
+	if err := uc.checkSecondFactor(user, code); err != nil {
+		uc.recordLoginFailure(user.Email, ip)
+		return domain.User{}, err
+	}
+	if _, err := uc.consumeToken(challengeToken, domain.TokenMFAChallenge); err != nil {
+		return domain.User{}, errors.New("invalid or expired mfa token")
+	}
+	if err := uc.throttleRepository.Reset(accountThrottleKey(user.Email)); err != nil {
+		fmt.Printf("Failed to reset login throttle: %v\n", err)
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Login Attempt",
+		Message:   fmt.Sprintf("User %s logged in successfully with a second factor", user.ID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log successful login attempt: %v\n", err)
+	}
+
 	return user, nil
 }
 
+// checkAccountState tells why the user may not sign in, if anything does
+// prevent it.
+func checkAccountState(user domain.User) error {
+	switch {
+	case !user.DeletedAt.IsZero():
+		return errors.New("user account has been deleted")
+	case !user.SuspendedAt.IsZero():
+		return errors.New("user account is suspended")
+	case !user.IsActive:
+		return errors.New("user account is not activated")
+	case user.PasswordResetRequired:
+		return errors.New("a password reset is required, check your email for the reset link")
+	}
+	return nil
+}
+
+// EnrollMFA generates a new TOTP secret for the user. It only takes effect
+// once confirmed with a code from the authenticator app.
+func (uc *userUsecase) EnrollMFA(userID string) (domain.MFAEnrollment, error) {
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	if user.MFA.Enabled {
+		return domain.MFAEnrollment{}, errors.New("multi-factor authentication is already enabled")
+	}
+	secret, err := infrastructures.GenerateTOTPSecret()
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	encrypted, err := infrastructures.EncryptSecret(secret)
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	mfa := user.MFA
+	mfa.PendingSecret = encrypted
+	if err := uc.userRepository.UpdateMFA(user.ID, mfa); err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	return domain.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: infrastructures.TOTPProvisioningURI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA enables MFA once the user proves their authenticator works and
+// returns a fresh set of recovery codes. The codes are only shown this once.
+func (uc *userUsecase) ConfirmMFA(userID, code string) ([]string, error) {
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFA.PendingSecret == "" {
+		return nil, errors.New("no pending multi-factor enrollment")
+	}
+	secret, err := infrastructures.DecryptSecret(user.MFA.PendingSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := infrastructures.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, errors.New("invalid verification code")
+	}
+	codes, err := infrastructures.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = infrastructures.HashOpaqueToken(c)
+	}
+	mfa := domain.MFA{
+		Enabled:       true,
+		Secret:        user.MFA.PendingSecret,
+		RecoveryCodes: hashes,
+		LastUsedStep:  step,
+	}
+	if err := uc.userRepository.UpdateMFA(user.ID, mfa); err != nil {
+		return nil, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "MFA Enrollment",
+		Message:   fmt.Sprintf("User %s enabled multi-factor authentication", user.ID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log mfa enrollment: %v\n", err)
+	}
+
+	return codes, nil
+}
+
+// DisableMFA turns MFA off after checking a current second factor. Admins
+// cannot disable it since admin access requires it.
+func (uc *userUsecase) DisableMFA(userID, code string) error {
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFA.Enabled {
+		return errors.New("multi-factor authentication is not enabled")
+	}
+	if user.IsAdmin {
+		return errors.New("multi-factor authentication is mandatory for admins")
+	}
+	if err := uc.checkSecondFactor(user, code); err != nil {
+		return err
+	}
+	if err := uc.userRepository.UpdateMFA(user.ID, domain.MFA{}); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "MFA Enrollment",
+		Message:   fmt.Sprintf("User %s disabled multi-factor authentication", user.ID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log mfa removal: %v\n", err)
+	}
+
+	return nil
+}
+
+// checkSecondFactor accepts either a current TOTP code, which can be used only
+// once, or an unused recovery code, which is consumed.
+func (uc *userUsecase) checkSecondFactor(user domain.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == 6 {
+		secret, err := infrastructures.DecryptSecret(user.MFA.Secret)
+		if err != nil {
+			return err
+		}
+		step, ok := infrastructures.ValidateTOTP(secret, code, time.Now())
+		if !ok {
+			return errors.New("invalid verification code")
+		}
+		fresh, err := uc.userRepository.UseTOTPStep(user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return errors.New("verification code has already been used")
+		}
+		return nil
+	}
+	used, err := uc.userRepository.ConsumeRecoveryCode(user.ID, infrastructures.HashOpaqueToken(strings.ToLower(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New("invalid verification code")
+	}
+	return nil
+}
+
 func (uc *userUsecase) GetProfile(userID string) (domain.User, error) {
 	user, err := uc.userRepository.GetByID(userID)
 	if err != nil {
@@ -116,12 +468,20 @@ func (uc *userUsecase) ForgetPassword(email string) error {
 	if !user.IsActive {
 		return errors.New("resetting unactivated account is not allowed")
 	}
-	expirationTime := time.Now().Add(1 * time.Hour)
-	token, err := infrastructures.GenerateVerificationToken(user.ID, email, "ForgetPassword", expirationTime)
+	err = uc.inTransaction(func(tx *userUsecase) error {
+		token, err := tx.issueToken(user.ID, email, domain.TokenPasswordReset, 1*time.Hour)
+		if err != nil {
+			return err
+		}
+		message, err := infrastructures.PasswordResetEmail(email, user.Language, token)
+		if err != nil {
+			return err
+		}
+		return enqueueEmail(tx.outboxRepository, message)
+	})
 	if err != nil {
 		return err
 	}
-	go infrastructures.SendPasswordResetEmail(email, token)
 	log := domain.SystemLog{
 		ID:        primitive.NewObjectID().Hex(),
 		Timestamp: time.Now(),
@@ -135,19 +495,37 @@ func (uc *userUsecase) ForgetPassword(email string) error {
 	return nil
 }
 
+// ResetPassword sets a new password with an emailed reset token. The token
+// is checked before the new password, and an unknown email is reported the
+// same way as a bad token, so the endpoint reveals nothing about accounts
+// or their passwords to callers without a valid token.
 func (uc *userUsecase) ResetPassword(token, email, newPassword string) error {
-	user, err := uc.userRepository.GetByEmail(email)
+	reset, err := uc.findToken(token, domain.TokenPasswordReset)
 	if err != nil {
 		return err
 	}
-	if err := infrastructures.ValidateVerificationToken(token, email, user.ID, "ForgetPassword"); err != nil {
+	user, err := uc.userRepository.GetByEmail(email)
+	if err != nil || reset.UserID != user.ID || reset.Email != email {
+		return repositories.ErrInvalidOneTimeToken
+	}
+	if err := checkPassword(newPassword, user); err != nil {
 		return err
 	}
-	hashedPassword, err := infrastructures.HashPassword(newPassword)
+	update, err := rotatePassword(user, newPassword)
 	if err != nil {
 		return err
 	}
-	if _, err := uc.userRepository.Update(user.ID, domain.User{Password: hashedPassword}); err != nil {
+	// The token is only spent once the new password is known to be valid.
+	// Consuming it is atomic, so a concurrent request cannot reuse it.
+	if _, err := uc.consumeToken(token, domain.TokenPasswordReset); err != nil {
+		return err
+	}
+	resetRequired := false
+	update.PasswordResetRequired = &resetRequired
+	if _, err := uc.userRepository.Update(user.ID, update); err != nil {
+		return err
+	}
+	if err := uc.tokenRepository.Invalidate(user.ID, domain.TokenPasswordReset); err != nil {
 		return err
 	}
 
@@ -164,34 +542,412 @@ func (uc *userUsecase) ResetPassword(token, email, newPassword string) error {
 	return nil
 }
 
-func (uc *userUsecase) RefreshAccessToken(refreshToken string) (string, error) {
-	claims, err := infrastructures.ValidateJWTToken(refreshToken)
+func (uc *userUsecase) GetAllUsers() ([]domain.User, error) {
+	users, err := uc.userRepository.Get()
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (uc *userUsecase) GetUserByID(userID string) (domain.User, error) {
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+// AssignRoles replaces a user's roles. The user's sessions are revoked so the
+// new roles take effect on their next login rather than when their access
+// token expires.
+func (uc *userUsecase) AssignRoles(actorID, userID string, roles []domain.Role) (domain.User, error) {
+	if actorID == userID {
+		return domain.User{}, errors.New("you cannot change your own roles")
+	}
+	assigned := []domain.Role{}
+	seen := map[domain.Role]bool{}
+	for _, role := range roles {
+		if !domain.ValidRole(role) {
+			return domain.User{}, fmt.Errorf("unknown role %s", role)
+		}
+		if !seen[role] {
+			seen[role] = true
+			assigned = append(assigned, role)
+		}
+	}
+	if _, err := uc.userRepository.GetByID(userID); err != nil {
+		return domain.User{}, err
+	}
+	if err := uc.userRepository.SetRoles(userID, assigned); err != nil {
+		return domain.User{}, err
+	}
+	if err := uc.sessionUsecase.RevokeAllSessions(userID); err != nil {
+		return domain.User{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Role Assignment",
+		Message:   fmt.Sprintf("Admin %s set the roles of user %s to %v", actorID, userID, assigned),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log role assignment: %v\n", err)
+	}
+
+	return uc.userRepository.GetByID(userID)
+}
+
+// SetUserActive suspends or reactivates an account. Suspending revokes the
+// user's sessions so they are signed out immediately. Reactivating only
+// lifts the suspension; it does not verify the user's email.
+func (uc *userUsecase) SetUserActive(actorID, userID string, active bool) (domain.User, error) {
+	if actorID == userID {
+		return domain.User{}, errors.New("you cannot change the status of your own account")
+	}
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !user.DeletedAt.IsZero() {
+		return domain.User{}, errors.New("deleted accounts cannot be suspended or reactivated")
+	}
+	action := "reactivated"
+	suspendedAt := time.Time{}
+	if !active {
+		action = "suspended"
+		suspendedAt = time.Now()
+	}
+	if err := uc.userRepository.SetSuspendedAt(userID, suspendedAt); err != nil {
+		return domain.User{}, err
+	}
+	user.SuspendedAt = suspendedAt
+	if !active {
+		if err := uc.sessionUsecase.RevokeAllSessions(userID); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "User Management",
+		Message:   fmt.Sprintf("Admin %s %s user %s", actorID, action, userID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log user status change: %v\n", err)
+	}
+
+	return user, nil
+}
+
+// ForcePasswordReset signs the user out everywhere and blocks their logins
+// until they set a new password through the emailed reset link.
+func (uc *userUsecase) ForcePasswordReset(actorID, userID string) error {
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	err = uc.inTransaction(func(tx *userUsecase) error {
+		token, err := tx.issueToken(user.ID, user.Email, domain.TokenPasswordReset, 24*time.Hour)
+		if err != nil {
+			return err
+		}
+		required := true
+		if _, err := tx.userRepository.Update(userID, domain.UserUpdate{PasswordResetRequired: &required}); err != nil {
+			return err
+		}
+		message, err := infrastructures.PasswordResetEmail(user.Email, user.Language, token)
+		if err != nil {
+			return err
+		}
+		return enqueueEmail(tx.outboxRepository, message)
+	})
+	if err != nil {
+		return err
+	}
+	if err := uc.sessionUsecase.RevokeAllSessions(userID); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "User Management",
+		Message:   fmt.Sprintf("Admin %s forced a password reset for user %s", actorID, userID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log forced password reset: %v\n", err)
+	}
+
+	return nil
+}
+
+// DeleteUser anonymizes a user the same way DeleteAccount does, keeping the
+// document their loans, API keys and action requests refer to. The last
+// super admin cannot be deleted.
+func (uc *userUsecase) DeleteUser(actorID, userID string) error {
+	if actorID == userID {
+		return errors.New("you cannot delete your own account")
+	}
+	user, err := uc.userRepository.GetByID(userID)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if !user.DeletedAt.IsZero() {
+		return errors.New("user has already been deleted")
+	}
+	for _, role := range domain.EffectiveRoles(user) {
+		if role != domain.RoleSuperAdmin {
+			continue
+		}
+		count, err := uc.userRepository.CountByRole(domain.RoleSuperAdmin)
+		if err != nil {
+			return err
+		}
+		if count <= 1 {
+			return errors.New("the last super admin cannot be deleted")
+		}
+	}
+	if err := uc.anonymizeUser(user); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "User Management",
+		Message:   fmt.Sprintf("Admin %s deleted user %s and their personal data was anonymized", actorID, userID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log user deletion: %v\n", err)
 	}
-	user, err := uc.userRepository.GetByID(claims.UserID)
+
+	return nil
+}
+
+// UnlockAccount lifts a login lockout using the link emailed when the
+// account was locked.
+func (uc *userUsecase) UnlockAccount(token, email string) error {
+	unlock, err := uc.consumeToken(token, domain.TokenAccountUnlock)
 	if err != nil {
-		return "", err
+		return err
 	}
-	accessToken, err := infrastructures.GenerateJWTToken(user, 1*time.Hour)
+	user, err := uc.userRepository.GetByID(unlock.UserID)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if unlock.Email != email || user.Email != email {
+		return repositories.ErrInvalidOneTimeToken
+	}
+	if err := uc.throttleRepository.Reset(accountThrottleKey(email)); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Account Lockout",
+		Message:   fmt.Sprintf("User %s unlocked their account", user.ID),
 	}
-	return accessToken, nil
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log account unlock: %v\n", err)
+	}
+
+	return nil
 }
 
-func (uc *userUsecase) GetAllUsers() ([]domain.User, error) {
-	users, err := uc.userRepository.Get()
+// ChangePassword sets a new password for a logged-in user after checking
+// their current one, and signs out all of their other sessions.
+func (uc *userUsecase) ChangePassword(userID, sessionID, currentPassword, newPassword string) error {
+	user, err := uc.userRepository.GetByID(userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return users, nil
+	if !infrastructures.ComparePassword(user.Password, currentPassword) {
+		return errors.New("current password is incorrect")
+	}
+	if err := checkPassword(newPassword, user); err != nil {
+		return err
+	}
+	update, err := rotatePassword(user, newPassword)
+	if err != nil {
+		return err
+	}
+	if _, err := uc.userRepository.Update(user.ID, update); err != nil {
+		return err
+	}
+	if err := uc.tokenRepository.Invalidate(user.ID, domain.TokenPasswordReset); err != nil {
+		return err
+	}
+	if err := uc.sessionUsecase.RevokeOtherSessions(user.ID, sessionID); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Password Change",
+		Message:   fmt.Sprintf("User %s changed their password", user.ID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log password change: %v\n", err)
+	}
+
+	return nil
 }
 
-func (uc *userUsecase) GetUserByID(userID string) (domain.User, error) {
+// RequestEmailChange emails a confirmation link to the new address. The
+// email is only changed once the link is followed, proving the user owns it.
+func (uc *userUsecase) RequestEmailChange(userID, newEmail, password string) error {
 	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !infrastructures.ComparePassword(user.Password, password) {
+		return errors.New("password is incorrect")
+	}
+	if newEmail == user.Email {
+		return errors.New("new email must differ from the current one")
+	}
+	if _, err := uc.userRepository.GetByEmail(newEmail); err == nil {
+		return errors.New("user exists with the given email")
+	}
+	err = uc.inTransaction(func(tx *userUsecase) error {
+		token, err := tx.issueToken(user.ID, newEmail, domain.TokenEmailChange, 1*time.Hour)
+		if err != nil {
+			return err
+		}
+		message, err := infrastructures.EmailChangeVerificationEmail(newEmail, user.Language, token)
+		if err != nil {
+			return err
+		}
+		return enqueueEmail(tx.outboxRepository, message)
+	})
+	if err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Email Change Request",
+		Message:   fmt.Sprintf("User %s requested to change their email to %s", user.ID, newEmail),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log email change request: %v\n", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange swaps in the new email carried by the token and signs
+// the user out everywhere, since their tokens carry the old address.
+func (uc *userUsecase) ConfirmEmailChange(token string) (domain.User, error) {
+	change, err := uc.consumeToken(token, domain.TokenEmailChange)
 	if err != nil {
 		return domain.User{}, err
 	}
-	return user, nil
+	user, err := uc.userRepository.GetByID(change.UserID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	oldEmail := user.Email
+	newEmail := change.Email
+	updatedUser, err := uc.userRepository.Update(user.ID, domain.UserUpdate{Email: &newEmail})
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err := uc.sessionUsecase.RevokeAllSessions(user.ID); err != nil {
+		return domain.User{}, err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Email Change",
+		Message:   fmt.Sprintf("User %s changed their email from %s to %s", user.ID, oldEmail, newEmail),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log email change: %v\n", err)
+	}
+
+	return updatedUser, nil
+}
+
+// UpdateProfile changes a user's own name and contact details. Other fields
+// of the update are ignored.
+func (uc *userUsecase) UpdateProfile(userID string, update domain.UserUpdate) (domain.User, error) {
+	if update.Name != nil && strings.TrimSpace(*update.Name) == "" {
+		return domain.User{}, errors.New("name cannot be empty")
+	}
+	if update.Language != nil && !validLanguage(*update.Language) {
+		return domain.User{}, errors.New("language must be a language tag such as en or fr-CA")
+	}
+	return uc.userRepository.Update(userID, domain.UserUpdate{
+		Name:     update.Name,
+		Phone:    update.Phone,
+		Address:  update.Address,
+		Language: update.Language,
+	})
+}
+
+// validLanguage accepts an empty value or a simple BCP 47 tag such as "en"
+// or "pt-BR".
+func validLanguage(language string) bool {
+	return language == "" || languageTag.MatchString(language)
+}
+
+// DeleteAccount anonymizes the user's personal data and signs them out. The
+// user document and their loan records are kept so loan history stays
+// intact. Staff accounts have to be removed by an administrator.
+func (uc *userUsecase) DeleteAccount(userID, password string) error {
+	user, err := uc.userRepository.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !infrastructures.ComparePassword(user.Password, password) {
+		return errors.New("password is incorrect")
+	}
+	if len(domain.EffectiveRoles(user)) > 0 {
+		return errors.New("staff accounts must be removed by an administrator")
+	}
+	if err := uc.anonymizeUser(user); err != nil {
+		return err
+	}
+
+	log := domain.SystemLog{
+		ID:        primitive.NewObjectID().Hex(),
+		Timestamp: time.Now(),
+		Category:  "Account Deletion",
+		Message:   fmt.Sprintf("User %s deleted their account and their personal data was anonymized", user.ID),
+	}
+	if err := uc.logRepository.Create(log); err != nil {
+		fmt.Printf("Failed to log account deletion: %v\n", err)
+	}
+
+	return nil
+}
+
+// anonymizeUser erases the user's personal data, including the queued and
+// undeliverable emails to their address, invalidates their emailed tokens
+// and signs them out, revoking their API keys too.
+func (uc *userUsecase) anonymizeUser(user domain.User) error {
+	now := time.Now()
+	if err := uc.userRepository.Anonymize(user.ID, now); err != nil {
+		return err
+	}
+	if err := uc.apiKeyRepository.RevokeByUser(user.ID, now); err != nil {
+		return err
+	}
+	if _, err := uc.outboxRepository.DeleteByRecipient(user.Email); err != nil {
+		return err
+	}
+	for _, purpose := range []string{domain.TokenPasswordReset, domain.TokenEmailChange} {
+		if err := uc.tokenRepository.Invalidate(user.ID, purpose); err != nil {
+			return err
+		}
+	}
+	return uc.sessionUsecase.RevokeAllSessions(user.ID)
 }
@@ -0,0 +1,86 @@
+package infrastructures
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+// IsBreachedPassword reports whether the password appears in a local copy of
+// a breached-password list. The file holds one "SHA1:COUNT" line per hash in
+// uppercase hex, sorted by hash, as produced by the Pwned Passwords
+// downloader. Only the hash is looked up, with a binary search over the
+// file, so the password never leaves the process.
+func IsBreachedPassword(path, password string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	sum := sha1.Sum([]byte(password))
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	// Find the first line starting at or after each probe offset; lo and hi
+	// always bound the region that may contain the hash.
+	lo, hi := int64(0), info.Size()
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := lineAtOrAfter(f, mid)
+		if err != nil {
+			return false, err
+		}
+		if line == "" || start >= hi {
+			hi = mid
+			continue
+		}
+		hash, _, _ := strings.Cut(line, ":")
+		switch strings.Compare(strings.ToUpper(hash), target) {
+		case 0:
+			return true, nil
+		case -1:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+	return false, nil
+}
+
+// lineAtOrAfter returns the first complete line starting at or after offset
+// and where it starts. It returns an empty line at the end of the file.
+func lineAtOrAfter(f *os.File, offset int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		// Step back one byte so a line starting exactly at offset is kept.
+		start = offset - 1
+	}
+	r := bufio.NewReader(io.NewSectionReader(f, start, 1<<62))
+	if offset > 0 {
+		skipped, err := r.ReadBytes('\n')
+		if err != nil {
+			return 0, "", ignoreEOF(err)
+		}
+		start += int64(len(skipped))
+	}
+	line, err := r.ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return start, "", ignoreEOF(err)
+	}
+	return start, string(bytes.TrimRight(line, "\r\n")), nil
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package infrastructures
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeBreachedList writes the passwords as a sorted SHA1:COUNT list, the
+// format of the Pwned Passwords download.
+func writeBreachedList(t *testing.T, newline string, passwords ...string) string {
+	t.Helper()
+	lines := make([]string, 0, len(passwords))
+	for i, password := range passwords {
+		sum := sha1.Sum([]byte(password))
+		lines = append(lines, strings.ToUpper(hex.EncodeToString(sum[:]))+":"+strings.Repeat("7", i+1))
+	}
+	sort.Strings(lines)
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := ""
+	if len(lines) > 0 {
+		content = strings.Join(lines, newline) + newline
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestIsBreachedPassword(t *testing.T) {
+	breached := []string{}
+	for i := 0; i < 200; i++ {
+		breached = append(breached, fmt.Sprintf("password%d", i))
+	}
+	for _, newline := range []string{"\n", "\r\n"} {
+		path := writeBreachedList(t, newline, breached...)
+		for _, password := range breached {
+			found, err := IsBreachedPassword(path, password)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !found {
+				t.Errorf("IsBreachedPassword(%q) = false with %q line endings, want true", password, newline)
+			}
+		}
+		for _, password := range []string{"", "correct horse battery staple", "Password", "password200"} {
+			found, err := IsBreachedPassword(path, password)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if found {
+				t.Errorf("IsBreachedPassword(%q) = true, want false", password)
+			}
+		}
+	}
+}
+
+func TestIsBreachedPasswordEdgeCases(t *testing.T) {
+	single := writeBreachedList(t, "\n", "hunter2")
+	if found, err := IsBreachedPassword(single, "hunter2"); err != nil || !found {
+		t.Errorf("single entry: found = %v, err = %v", found, err)
+	}
+	if found, err := IsBreachedPassword(single, "hunter3"); err != nil || found {
+		t.Errorf("single entry, other password: found = %v, err = %v", found, err)
+	}
+
+	empty := writeBreachedList(t, "\n")
+	if found, err := IsBreachedPassword(empty, "hunter2"); err != nil || found {
+		t.Errorf("empty list: found = %v, err = %v", found, err)
+	}
+
+	if _, err := IsBreachedPassword(filepath.Join(t.TempDir(), "missing.txt"), "hunter2"); err == nil {
+		t.Errorf("missing list: expected an error")
+	}
+}
@@ -0,0 +1,224 @@
+package infrastructures
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"loan-management/config"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// DefaultEmailLocale is used when no template exists for the user's language.
+const DefaultEmailLocale = "en"
+
+const defaultEmailTemplatesDir = "templates/email"
+
+// Email template names.
+const (
+	EmailVerification  = "verification"
+	EmailPasswordReset = "password_reset"
+	EmailAccountUnlock = "account_unlock"
+	EmailChange        = "email_change"
+	EmailRateReset     = "rate_reset"
+
+	// Borrower notifications, named after their domain.NotificationType.
+	EmailLoanSubmitted  = "loan_submitted"
+	EmailLoanApproved   = "loan_approved"
+	EmailLoanRejected   = "loan_rejected"
+	EmailLoanDisbursed  = "loan_disbursed"
+	EmailPaymentDue     = "payment_due"
+	EmailPaymentOverdue = "payment_overdue"
+)
+
+var ErrEmailTemplateNotFound = errors.New("email template not found")
+
+// RenderedEmail is an email rendered in one locale, with an HTML body and a
+// plain-text alternative. Content is the plain-text body without the layout.
+type RenderedEmail struct {
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+	Content string `json:"-"`
+}
+
+// emailView is what the layouts are executed with; templates see Data.
+type emailView struct {
+	Locale string
+	Data   interface{}
+}
+
+// emailSamples holds example data for previewing each template.
+var emailSamples = map[string]interface{}{
+	EmailVerification:  map[string]interface{}{"Email": "jane@example.com", "Link": "https://example.com/users/verify-email?email=jane@example.com&token=sample"},
+	EmailPasswordReset: map[string]interface{}{"Email": "jane@example.com", "Link": "https://example.com/users/password-update?email=jane@example.com&token=sample"},
+	EmailAccountUnlock: map[string]interface{}{"Email": "jane@example.com", "Link": "https://example.com/users/unlock?email=jane@example.com&token=sample"},
+	EmailChange:        map[string]interface{}{"Email": "jane@example.com", "Link": "https://example.com/users/email/confirm?token=sample"},
+	EmailRateReset: map[string]interface{}{
+		"LoanID":        "665f1c2e8b3a4d0012345678",
+		"Rate":          7.25,
+		"Payment":       1043.17,
+		"EffectiveDate": time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC),
+	},
+	EmailLoanSubmitted: map[string]interface{}{"LoanID": "665f1c2e8b3a4d0012345678", "Amount": "25000"},
+	EmailLoanApproved: map[string]interface{}{
+		"LoanID":         "665f1c2e8b3a4d0012345678",
+		"Amount":         25000.0,
+		"Rate":           7.25,
+		"TermMonths":     24,
+		"MonthlyPayment": 1122.94,
+		"ExpiresAt":      time.Date(2024, time.July, 8, 0, 0, 0, 0, time.UTC),
+	},
+	EmailLoanRejected: map[string]interface{}{"LoanID": "665f1c2e8b3a4d0012345678"},
+	EmailLoanDisbursed: map[string]interface{}{
+		"LoanID":       "665f1c2e8b3a4d0012345678",
+		"Amount":       "25000",
+		"Payment":      1122.94,
+		"FirstDueDate": time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC),
+	},
+	EmailPaymentDue: map[string]interface{}{
+		"LoanID":  "665f1c2e8b3a4d0012345678",
+		"Number":  3,
+		"Payment": 1122.94,
+		"DueDate": time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC),
+	},
+	EmailPaymentOverdue: map[string]interface{}{
+		"LoanID":  "665f1c2e8b3a4d0012345678",
+		"Number":  3,
+		"Payment": 1122.94,
+		"DueDate": time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+func emailTemplatesDir() string {
+	config, err := config.LoadConfig()
+	if err != nil || config.Email.TemplatesDir == "" {
+		return defaultEmailTemplatesDir
+	}
+	return config.Email.TemplatesDir
+}
+
+// RenderEmail renders the named template for the locale, falling back from
+// a regional variant ("fr-ca") to its language ("fr") and then to the default
+// locale. Both bodies are wrapped in the shared layouts in layouts/, and the
+// subject is the "subject" block of the text template.
+func RenderEmail(name, locale string, data interface{}) (RenderedEmail, error) {
+	dir := emailTemplatesDir()
+	locale, ok := resolveEmailLocale(dir, name, locale)
+	if !ok {
+		return RenderedEmail{}, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
+	view := emailView{Locale: locale, Data: data}
+
+	text, err := texttemplate.ParseFiles(
+		filepath.Join(dir, "layouts", "base.txt"),
+		filepath.Join(dir, locale, name+".txt"),
+	)
+	if err != nil {
+		return RenderedEmail{}, err
+	}
+	var subject, content, textBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return RenderedEmail{}, err
+	}
+	if err := text.ExecuteTemplate(&content, "content", data); err != nil {
+		return RenderedEmail{}, err
+	}
+	if err := text.ExecuteTemplate(&textBody, "layout", view); err != nil {
+		return RenderedEmail{}, err
+	}
+
+	html, err := htmltemplate.ParseFiles(
+		filepath.Join(dir, "layouts", "base.html"),
+		filepath.Join(dir, locale, name+".html"),
+	)
+	if err != nil {
+		return RenderedEmail{}, err
+	}
+	var htmlBody bytes.Buffer
+	if err := html.ExecuteTemplate(&htmlBody, "layout", view); err != nil {
+		return RenderedEmail{}, err
+	}
+
+	return RenderedEmail{
+		Locale:  locale,
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    htmlBody.String(),
+		Text:    strings.TrimLeft(textBody.String(), "\n"),
+		Content: strings.TrimSpace(content.String()),
+	}, nil
+}
+
+// PreviewEmail renders the named template with example data.
+func PreviewEmail(name, locale string) (RenderedEmail, error) {
+	data, ok := emailSamples[name]
+	if !ok {
+		return RenderedEmail{}, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
+	return RenderEmail(name, locale, data)
+}
+
+// EmailTemplateLocales lists each known template with the locales it has
+// been translated to.
+func EmailTemplateLocales() (map[string][]string, error) {
+	dir := emailTemplatesDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	templates := map[string][]string{}
+	for name := range emailSamples {
+		templates[name] = []string{}
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "layouts" {
+			continue
+		}
+		for name := range templates {
+			if emailTemplateExists(dir, entry.Name(), name) {
+				templates[name] = append(templates[name], entry.Name())
+			}
+		}
+	}
+	for name := range templates {
+		sort.Strings(templates[name])
+	}
+	return templates, nil
+}
+
+func resolveEmailLocale(dir, name, locale string) (string, bool) {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	candidates := []string{}
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if i := strings.Index(locale, "-"); i > 0 {
+			candidates = append(candidates, locale[:i])
+		}
+	}
+	candidates = append(candidates, DefaultEmailLocale)
+	for _, candidate := range candidates {
+		// The locale is used as a path element, so only plain tags qualify.
+		if strings.ContainsAny(candidate, `/\.`) {
+			continue
+		}
+		if emailTemplateExists(dir, candidate, name) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func emailTemplateExists(dir, locale, name string) bool {
+	for _, ext := range []string{".html", ".txt"} {
+		if _, err := os.Stat(filepath.Join(dir, locale, name+ext)); err != nil {
+			return false
+		}
+	}
+	return true
+}
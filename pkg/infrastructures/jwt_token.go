@@ -1,58 +1,142 @@
 package infrastructures
 
 import (
+	"crypto"
+	"errors"
 	"fmt"
 	"loan-management/config"
 	"loan-management/internal/domain"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	defaultIssuer    = "loan-management"
+	defaultAudience  = "loan-management-api"
+	defaultClockSkew = 30 * time.Second
+)
+
+// UserClaims are the claims of an access token. The user ID is carried in
+// the standard sub claim.
 type UserClaims struct {
-	jwt.StandardClaims
-	UserID  string
-	Email   string
-	IsAdmin bool
+	jwt.RegisteredClaims
+	Email     string        `json:"email"`
+	IsAdmin   bool          `json:"is_admin"`
+	SessionID string        `json:"sid"`
+	MFA       bool          `json:"mfa"`
+	Roles     []domain.Role `json:"roles,omitempty"`
 }
 
-func GenerateJWTToken(user domain.User, t time.Duration) (string, error) {
+// JWTSigningKey is a private key used to sign access tokens, identified in
+// the token header by its key ID.
+type JWTSigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+}
+
+// PublicKeyLookup returns the public key and algorithm registered for a key ID.
+type PublicKeyLookup func(kid string) (crypto.PublicKey, string, error)
+
+func GenerateJWTToken(user domain.User, session domain.Session, t time.Duration, key JWTSigningKey) (string, error) {
 	config, err := config.LoadConfig()
 	if err != nil {
 		return "", err
 	}
+	method := jwt.GetSigningMethod(key.Algorithm)
+	if method == nil || !isAsymmetric(key.Algorithm) {
+		return "", fmt.Errorf("unsupported signing algorithm %s", key.Algorithm)
+	}
+	now := time.Now()
 	claims := UserClaims{
-		UserID:         user.ID,
-		Email:          user.Email,
-		IsAdmin:        user.IsAdmin,
-		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(t).Unix()},
+		Email:     user.Email,
+		IsAdmin:   user.IsAdmin,
+		SessionID: session.ID,
+		MFA:       session.MFA,
+		Roles:     domain.EffectiveRoles(user),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        primitive.NewObjectID().Hex(),
+			Subject:   user.ID,
+			Issuer:    tokenIssuer(config),
+			Audience:  jwt.ClaimStrings{tokenAudience(config)},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t)),
+		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.Jwt.Secret))
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 		return "", err
 	}
 	return tokenString, nil
 }
 
-func ValidateJWTToken(tokenString string) (*UserClaims, error) {
+// ValidateJWTToken verifies a token against the key named by its kid header.
+// Only asymmetric algorithms are accepted and the token must use the one
+// registered for its key. Issuer, audience, expiry, not-before and issued-at
+// are checked with the configured clock skew, and a jti is required.
+func ValidateJWTToken(tokenString string, lookup PublicKeyLookup) (*UserClaims, error) {
 	config, err := config.LoadConfig()
 	if err != nil {
 		return nil, err
 	}
-	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return []byte(config.Jwt.Secret), nil
-	})
+	claims := &UserClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no key id")
+		}
+		key, algorithm, err := lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		if t.Method.Alg() != algorithm {
+			return nil, fmt.Errorf("unexpected signing algorithm %s", t.Method.Alg())
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}),
+		jwt.WithIssuer(tokenIssuer(config)),
+		jwt.WithAudience(tokenAudience(config)),
+		jwt.WithLeeway(clockSkew(config)),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
+	if claims.ID == "" || claims.Subject == "" {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
 
-	if claims, ok := token.Claims.(*UserClaims); ok && token.Valid {
-		if claims.ExpiresAt < time.Now().Unix() {
-			return nil, fmt.Errorf("token has expired")
-		}
-		return claims, nil
+func isAsymmetric(algorithm string) bool {
+	return algorithm == AlgorithmRS256 || algorithm == AlgorithmEdDSA
+}
+
+func tokenIssuer(config config.Config) string {
+	if config.Jwt.Issuer != "" {
+		return config.Jwt.Issuer
 	}
+	return defaultIssuer
+}
 
-	return nil, fmt.Errorf("invalid token")
+func tokenAudience(config config.Config) string {
+	if config.Jwt.Audience != "" {
+		return config.Jwt.Audience
+	}
+	return defaultAudience
+}
+
+func clockSkew(config config.Config) time.Duration {
+	skew, err := time.ParseDuration(config.Jwt.ClockSkew)
+	if err != nil || skew < 0 {
+		return defaultClockSkew
+	}
+	return skew
 }
@@ -0,0 +1,248 @@
+package infrastructures
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"loan-management/config"
+	"loan-management/internal/domain"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Email transports.
+const (
+	TransportSMTP    = "smtp"
+	TransportFile    = "file"
+	TransportConsole = "console"
+	TransportMemory  = "memory"
+)
+
+// SMTP TLS modes and authentication mechanisms.
+const (
+	SMTPTLSStartTLS = "starttls"
+	SMTPTLSImplicit = "tls"
+	SMTPTLSNone     = "none"
+
+	SMTPAuthPlain   = "plain"
+	SMTPAuthCRAMMD5 = "crammd5"
+	SMTPAuthNone    = "none"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// NewMailer builds the transport selected by the email configuration.
+func NewMailer(config config.Email) (domain.Mailer, error) {
+	switch config.Transport {
+	case "", TransportSMTP:
+		return NewSMTPMailer(config)
+	case TransportFile:
+		dir := config.OutputDir
+		if dir == "" {
+			dir = "mail"
+		}
+		return &FileMailer{From: config.Address, Dir: dir}, nil
+	case TransportConsole:
+		return &ConsoleMailer{From: config.Address, Out: os.Stdout}, nil
+	case TransportMemory:
+		return &MemoryMailer{}, nil
+	}
+	return nil, fmt.Errorf("unknown email transport %q", config.Transport)
+}
+
+// SMTPMailer delivers emails through an SMTP server.
+type SMTPMailer struct {
+	From     string
+	Host     string
+	Port     string
+	TLS      string
+	Auth     string
+	Username string
+	Password string
+}
+
+// NewSMTPMailer configures an SMTP transport, defaulting to STARTTLS on
+// port 587 with PLAIN authentication as the email address.
+func NewSMTPMailer(config config.Email) (*SMTPMailer, error) {
+	m := &SMTPMailer{
+		From:     config.Address,
+		Host:     config.SMTP.Host,
+		Port:     config.SMTP.Port,
+		TLS:      config.SMTP.TLS,
+		Auth:     config.SMTP.Auth,
+		Username: config.SMTP.Username,
+		Password: config.SMTP.Password,
+	}
+	if m.Host == "" {
+		m.Host = "smtp.gmail.com"
+	}
+	if m.TLS == "" {
+		m.TLS = SMTPTLSStartTLS
+	}
+	if m.Port == "" {
+		m.Port = "587"
+		if m.TLS == SMTPTLSImplicit {
+			m.Port = "465"
+		}
+	}
+	if m.Auth == "" {
+		m.Auth = SMTPAuthPlain
+	}
+	if m.Username == "" {
+		m.Username = config.Address
+	}
+	if m.Password == "" {
+		m.Password = config.Key
+	}
+	switch m.TLS {
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return nil, fmt.Errorf("unknown smtp tls mode %q", m.TLS)
+	}
+	switch m.Auth {
+	case SMTPAuthPlain, SMTPAuthCRAMMD5, SMTPAuthNone:
+	default:
+		return nil, fmt.Errorf("unknown smtp auth mechanism %q", m.Auth)
+	}
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(email domain.EmailMessage) error {
+	message, err := buildMessage(m.From, email)
+	if err != nil {
+		return err
+	}
+	address := net.JoinHostPort(m.Host, m.Port)
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	tlsConfig := &tls.Config{ServerName: m.Host}
+
+	var conn net.Conn
+	if m.TLS == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.TLS == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	// PLAIN authentication is refused by net/smtp over unencrypted
+	// connections to anything but localhost.
+	switch m.Auth {
+	case SMTPAuthPlain:
+		err = client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host))
+	case SMTPAuthCRAMMD5:
+		err = client.Auth(smtp.CRAMMD5Auth(m.Username, m.Password))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	for _, to := range email.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// FileMailer writes each email as an .eml file into Dir, for development.
+type FileMailer struct {
+	From string
+	Dir  string
+}
+
+func (m *FileMailer) Send(email domain.EmailMessage) error {
+	message, err := buildMessage(m.From, email)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix) + ".eml"
+	return os.WriteFile(filepath.Join(m.Dir, name), message, 0o600)
+}
+
+// ConsoleMailer prints the plain-text version of each email, for
+// development.
+type ConsoleMailer struct {
+	From string
+	Out  io.Writer
+	mu   sync.Mutex
+}
+
+func (m *ConsoleMailer) Send(email domain.EmailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.Out, "----- email -----\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n-----------------\n",
+		m.From, strings.Join(email.To, ", "), email.Subject, email.Text)
+	return err
+}
+
+// MemoryMailer keeps sent emails in memory so tests can inspect them.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []domain.EmailMessage
+}
+
+func (m *MemoryMailer) Send(email domain.EmailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, email)
+	return nil
+}
+
+// Messages returns the emails sent so far, oldest first.
+func (m *MemoryMailer) Messages() []domain.EmailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.EmailMessage(nil), m.messages...)
+}
+
+// Reset forgets the emails sent so far.
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
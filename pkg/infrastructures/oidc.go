@@ -0,0 +1,339 @@
+package infrastructures
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oidcCacheTTL is how long discovery documents and key sets are reused
+// before they are fetched again.
+const oidcCacheTTL = time.Hour
+
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// OIDCProvider holds the endpoints of an identity provider as published in
+// its discovery document.
+type OIDCProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// IDTokenClaims are the validated claims of an ID token. Claims holds every
+// claim so provider specific ones, such as groups, can be read.
+type IDTokenClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	AMR           []string
+	Claims        jwt.MapClaims
+}
+
+type oidcCache struct {
+	mu        sync.Mutex
+	providers map[string]cachedProvider
+	keys      map[string]cachedKeys
+}
+
+type cachedProvider struct {
+	provider  OIDCProvider
+	fetchedAt time.Time
+}
+
+type cachedKeys struct {
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+var providerCache = &oidcCache{
+	providers: map[string]cachedProvider{},
+	keys:      map[string]cachedKeys{},
+}
+
+// DiscoverOIDCProvider loads the discovery document of issuer.
+func DiscoverOIDCProvider(issuer string) (OIDCProvider, error) {
+	providerCache.mu.Lock()
+	cached, ok := providerCache.providers[issuer]
+	providerCache.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < oidcCacheTTL {
+		return cached.provider, nil
+	}
+
+	var provider OIDCProvider
+	if err := getJSON(strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &provider); err != nil {
+		return OIDCProvider{}, fmt.Errorf("loading discovery document: %v", err)
+	}
+	if provider.Issuer != issuer {
+		return OIDCProvider{}, fmt.Errorf("discovery document is for issuer %q, expected %q", provider.Issuer, issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return OIDCProvider{}, errors.New("discovery document is missing required endpoints")
+	}
+
+	providerCache.mu.Lock()
+	providerCache.providers[issuer] = cachedProvider{provider: provider, fetchedAt: time.Now()}
+	providerCache.mu.Unlock()
+	return provider, nil
+}
+
+// GeneratePKCE returns a PKCE code verifier and its S256 code challenge.
+func GeneratePKCE() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(b)
+	return verifier, pkceChallenge(verifier), nil
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyPKCE reports whether verifier matches an S256 code challenge.
+func VerifyPKCE(verifier, challenge string) bool {
+	return verifier != "" && pkceChallenge(verifier) == challenge
+}
+
+// OIDCAuthorizationURL builds the URL the browser is sent to in order to log
+// in at the provider.
+func OIDCAuthorizationURL(provider OIDCProvider, clientID, redirectURL string, scopes []string, state, nonce, challenge string) (string, error) {
+	u, err := url.Parse(provider.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", clientID)
+	q.Set("redirect_uri", redirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// ExchangeAuthorizationCode redeems an authorization code at the token
+// endpoint and returns the raw ID token.
+func ExchangeAuthorizationCode(provider OIDCProvider, clientID, clientSecret, redirectURL, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"client_id":     {clientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+	resp, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("decoding token response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+	return body.IDToken, nil
+}
+
+// ValidateIDToken checks the signature of an ID token against the provider's
+// key set along with its issuer, audience, expiry and nonce.
+func ValidateIDToken(provider OIDCProvider, rawToken, clientID, nonce string, leeway time.Duration) (IDTokenClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return providerKey(provider.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(provider.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	)
+	if err != nil {
+		return IDTokenClaims{}, fmt.Errorf("invalid id token: %v", err)
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return IDTokenClaims{}, errors.New("invalid id token: nonce mismatch")
+	}
+	// With several audiences the token must have been issued to this client.
+	if azp, ok := claims["azp"].(string); ok && azp != clientID {
+		return IDTokenClaims{}, errors.New("invalid id token: authorized party mismatch")
+	}
+
+	result := IDTokenClaims{Claims: claims, AMR: ClaimStrings(claims, "amr")}
+	result.Subject, _ = claims["sub"].(string)
+	result.Email, _ = claims["email"].(string)
+	result.Name, _ = claims["name"].(string)
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = verified
+	case string:
+		result.EmailVerified = verified == "true"
+	}
+	if result.Subject == "" {
+		return IDTokenClaims{}, errors.New("invalid id token: missing subject")
+	}
+	return result, nil
+}
+
+// ClaimStrings reads a claim that is either a string or a list of strings.
+func ClaimStrings(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// providerKey returns the key with the given ID from the provider's key set,
+// refetching the set once when the key is unknown so rotations are picked up.
+func providerKey(jwksURI, kid string) (crypto.PublicKey, error) {
+	providerCache.mu.Lock()
+	cached, ok := providerCache.keys[jwksURI]
+	providerCache.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < oidcCacheTTL {
+		if key, found := cached.keys[kid]; found {
+			return key, nil
+		}
+	}
+
+	var set struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+	if err := getJSON(jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("loading key set: %v", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := ParseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	providerCache.mu.Lock()
+	providerCache.keys[jwksURI] = cachedKeys{keys: keys, fetchedAt: time.Now()}
+	providerCache.mu.Unlock()
+
+	key, found := keys[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// ParseJWK converts an RSA, EC or Ed25519 JWK into a public key.
+func ParseJWK(jwk JSONWebKey) (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+}
+
+func getJSON(url string, v interface{}) error {
+	resp, err := oidcHTTPClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
@@ -1,78 +1,117 @@
 package infrastructures
 
 import (
+	"bytes"
 	"fmt"
 	"loan-management/config"
-	"net/smtp"
+	"loan-management/internal/domain"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
 )
 
-func sendEmail(subject, data string, to []string) error {
-	config, err := config.LoadConfig()
-	if err != nil {
-		return err
+// buildMessage encodes the email as multipart/alternative with the plain-text
+// part first, so clients that can show HTML prefer it.
+func buildMessage(from string, email domain.EmailMessage) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	parts := []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", email.Text},
+		{"text/html; charset=UTF-8", email.HTML},
+	}
+	for _, p := range parts {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
 	}
-	from := config.Email.Address
-	key := config.Email.Key
-	host := "smtp.gmail.com"
-	port := "587"
-	address := host + ":" + port
-	message := []byte("Subject: " + subject + "\n" + "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n" + data)
-	auth := smtp.PlainAuth("", from, key, host)
-	if err = smtp.SendMail(address, auth, from, to, message); err != nil {
-		return err
+	if err := writer.Close(); err != nil {
+		return nil, err
 	}
-	return nil
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(email.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", email.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", writer.Boundary())
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
 }
 
-func SendVerificationEmail(email, token string) error {
+// renderMessage renders the named template in the recipient's language.
+func renderMessage(name, locale, to string, data interface{}) (domain.EmailMessage, error) {
+	email, err := RenderEmail(name, locale, data)
+	if err != nil {
+		return domain.EmailMessage{}, err
+	}
+	return domain.EmailMessage{
+		Template: name,
+		To:       []string{to},
+		Subject:  email.Subject,
+		Text:     email.Text,
+		HTML:     email.HTML,
+	}, nil
+}
+
+func VerificationEmail(email, locale, token string) (domain.EmailMessage, error) {
 	config, err := config.LoadConfig()
 	if err != nil {
-		return err
+		return domain.EmailMessage{}, err
 	}
 	verificationLink := fmt.Sprintf(config.Server.Url+config.Server.Port+"/users/verify-email?email=%s&token=%s", email, token)
-	subject := "Verify Your Email Address"
-	body := fmt.Sprintf(`
-		<!DOCTYPE html>
-		<html lang="en">
-		<head>
-			<meta charset="UTF-8">
-			<meta name="viewport" content="width=device-width, initial-scale=1.0">
-			<title>Email Verification</title>
-		</head>
-		<body>
-			<p>Hello,</p>
-			<p>Thank you for registering with Loan Manager! Please click the link below to verify your email address:</p>
-			<p><a href="%s">Verify Email</a></p>
-			<p>If you did not register for this account, you can ignore this email.</p>
-			<p>Best regards,<br>The Loan Manager Team</p>
-		</body>
-		</html>`, verificationLink)
-	return sendEmail(subject, body, []string{email})
+	return renderMessage(EmailVerification, locale, email, map[string]interface{}{"Email": email, "Link": verificationLink})
 }
 
-func SendPasswordResetEmail(email, token string) error {
+func PasswordResetEmail(email, locale, token string) (domain.EmailMessage, error) {
 	config, err := config.LoadConfig()
 	if err != nil {
-		return err
+		return domain.EmailMessage{}, err
 	}
 
 	resetLink := fmt.Sprintf("%s%s/users/password-update?email=%s&token=%s", config.Server.Url, config.Server.Port, email, token)
-	subject := "Reset Your Password"
-	body := fmt.Sprintf(`
-        <!DOCTYPE html>
-        <html lang="en">
-        <head>
-            <meta charset="UTF-8">
-            <meta name="viewport" content="width=device-width, initial-scale=1.0">
-            <title>Password Reset</title>
-        </head>
-        <body>
-            <p>Hello,</p>
-            <p>We received a request to reset your password. Please click the link below to choose a new password:</p>
-            <p><a href="%s">Reset Password</a></p>
-            <p>If you did not request a password reset, you can ignore this email.</p>
-            <p>Best regards,<br>The Loan Manager Team</p>
-        </body>
-        </html>`, resetLink)
-	return sendEmail(subject, body, []string{email})
+	return renderMessage(EmailPasswordReset, locale, email, map[string]interface{}{"Email": email, "Link": resetLink})
+}
+
+func AccountUnlockEmail(email, locale, token string) (domain.EmailMessage, error) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return domain.EmailMessage{}, err
+	}
+
+	unlockLink := fmt.Sprintf("%s%s/users/unlock?email=%s&token=%s", config.Server.Url, config.Server.Port, email, token)
+	return renderMessage(EmailAccountUnlock, locale, email, map[string]interface{}{"Email": email, "Link": unlockLink})
+}
+
+func EmailChangeVerificationEmail(email, locale, token string) (domain.EmailMessage, error) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return domain.EmailMessage{}, err
+	}
+
+	confirmLink := fmt.Sprintf("%s%s/users/email/confirm?token=%s", config.Server.Url, config.Server.Port, token)
+	return renderMessage(EmailChange, locale, email, map[string]interface{}{"Email": email, "Link": confirmLink})
+}
+
+func RateResetEmail(email, locale, loanID string, rate, payment float64, effectiveDate time.Time) (domain.EmailMessage, error) {
+	return renderMessage(EmailRateReset, locale, email, map[string]interface{}{
+		"LoanID":        loanID,
+		"Rate":          rate * 100,
+		"Payment":       payment,
+		"EffectiveDate": effectiveDate,
+	})
 }
@@ -0,0 +1,124 @@
+package infrastructures
+
+import (
+	"bytes"
+	"io"
+	"loan-management/internal/domain"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestBuildMessage(t *testing.T) {
+	email := domain.EmailMessage{
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Subject: "Réinitialisation du mot de passe",
+		Text:    "Bonjour,\nVotre lien : https://example.com/reset?token=abc&email=alice@example.com",
+		HTML:    `<p>Bonjour,</p><p><a href="https://example.com/reset?token=abc">Réinitialiser</a></p>`,
+	}
+	raw, err := buildMessage("noreply@example.com", email)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("message does not parse: %v", err)
+	}
+
+	if got := msg.Header.Get("From"); got != "noreply@example.com" {
+		t.Errorf("From = %q", got)
+	}
+	if got := msg.Header.Get("To"); got != "alice@example.com, bob@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != email.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, email.Subject)
+	}
+	if msg.Header.Get("Date") == "" || msg.Header.Get("MIME-Version") != "1.0" {
+		t.Errorf("missing Date or MIME-Version header")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	want := []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", email.Text},
+		{"text/html; charset=UTF-8", email.HTML},
+	}
+	for _, w := range want {
+		part, err := reader.NextRawPart()
+		if err != nil {
+			t.Fatalf("reading %s part: %v", w.contentType, err)
+		}
+		if got := part.Header.Get("Content-Type"); got != w.contentType {
+			t.Errorf("part Content-Type = %q, want %q", got, w.contentType)
+		}
+		if got := part.Header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+			t.Errorf("part Content-Transfer-Encoding = %q", got)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("decoding %s part: %v", w.contentType, err)
+		}
+		// Quoted-printable bodies use CRLF line breaks, as MIME requires.
+		if got := strings.ReplaceAll(string(body), "\r\n", "\n"); got != w.body {
+			t.Errorf("%s body = %q, want %q", w.contentType, got, w.body)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("expected exactly two parts, got error %v", err)
+	}
+}
+
+func TestBuildMessageSubjectCannotInjectHeaders(t *testing.T) {
+	raw, err := buildMessage("noreply@example.com", domain.EmailMessage{
+		To:      []string{"alice@example.com"},
+		Subject: "Hello\r\nBcc: mallory@example.com",
+		Text:    "text",
+		HTML:    "<p>html</p>",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("message does not parse: %v", err)
+	}
+	if bcc := msg.Header.Get("Bcc"); bcc != "" {
+		t.Errorf("subject injected a Bcc header: %q", bcc)
+	}
+	if strings.Contains(msg.Header.Get("Subject"), "\n") {
+		t.Errorf("subject header contains a line break")
+	}
+}
+
+func TestMemoryMailer(t *testing.T) {
+	mailer := &MemoryMailer{}
+	first := domain.EmailMessage{Template: EmailVerification, To: []string{"alice@example.com"}, Subject: "Verify"}
+	second := domain.EmailMessage{Template: EmailPasswordReset, To: []string{"bob@example.com"}, Subject: "Reset"}
+	for _, email := range []domain.EmailMessage{first, second} {
+		if err := mailer.Send(email); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	messages := mailer.Messages()
+	if len(messages) != 2 || messages[0].Subject != "Verify" || messages[1].Subject != "Reset" {
+		t.Fatalf("Messages() = %+v, want both emails in order", messages)
+	}
+	messages[0].Subject = "changed"
+	if mailer.Messages()[0].Subject != "Verify" {
+		t.Errorf("Messages() returned the mailer's own slice")
+	}
+
+	mailer.Reset()
+	if got := mailer.Messages(); len(got) != 0 {
+		t.Errorf("Messages() after Reset = %+v", got)
+	}
+}